@@ -0,0 +1,335 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package atlas packs many images into a few large ones, called pages, so sprites
+// drawn from the same page share textures and can be drawn together by a
+// sprite.Batch.
+package atlas
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+
+	"github.com/hurricanerix/shade/sprite"
+)
+
+// Default options of a Builder.
+const (
+	DefaultMaxSize = 2048
+	DefaultPadding = 2
+)
+
+// flatNormal is used for the normal map of images added without one.
+var flatNormal = color.RGBA{128, 128, 255, 255}
+
+// Builder collects images to pack into an Atlas.
+type Builder struct {
+	// MaxSize of a page's width and height in pixels, DefaultMaxSize if zero.
+	MaxSize int
+	// Padding around each image in pixels, DefaultPadding if zero, or none if
+	// negative.  The padding repeats the image's edge pixels, so texture filtering
+	// at the edges does not bleed in neighbouring images.
+	Padding int
+	inputs  []input
+}
+
+type input struct {
+	name      string
+	colorMap  image.Image
+	normalMap image.Image
+	framesX   int
+	framesY   int
+}
+
+// Add colorMap, and its normalMap which may be nil, as the region name made of
+// framesX by framesY frames.
+func (b *Builder) Add(name string, colorMap, normalMap image.Image, framesX, framesY int) {
+	b.inputs = append(b.inputs, input{
+		name:      name,
+		colorMap:  colorMap,
+		normalMap: normalMap,
+		framesX:   framesX,
+		framesY:   framesY,
+	})
+}
+
+// Region of an atlas page holding one of the images added to the Builder.
+type Region struct {
+	Name string
+	// Page the region is on.
+	Page int
+	// Bounds of the region on the page in pixels, without padding.
+	Bounds  image.Rectangle
+	FramesX int
+	FramesY int
+}
+
+// Page of an atlas.
+type Page struct {
+	ColorMap *image.RGBA
+	// NormalMap is nil if none of the images on the page have one.
+	NormalMap *image.RGBA
+	sprite    *sprite.Context
+}
+
+// Atlas of packed images.
+type Atlas struct {
+	Pages   []*Page
+	Regions map[string]Region
+	// Sampling of the pages' textures, set before the first call to Sprite.
+	// Packing pads regions with their edge pixels, so Linear filtering does not
+	// bleed between them.  Mipmaps average more texels than the padding at smaller
+	// levels, so they are limited to the levels the padding keeps apart, and not
+	// used without padding.
+	Sampling sprite.Sampling
+	padding  int
+}
+
+// Build packs the added images into as few pages as it can.
+func (b *Builder) Build() (*Atlas, error) {
+	maxSize := b.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	padding := b.Padding
+	if padding == 0 {
+		padding = DefaultPadding
+	} else if padding < 0 {
+		padding = 0
+	}
+
+	rects := make([]image.Point, len(b.inputs))
+	names := make(map[string]bool, len(b.inputs))
+	for i, in := range b.inputs {
+		if names[in.name] {
+			return nil, fmt.Errorf("could not pack %s: name is used more than once", in.name)
+		}
+		names[in.name] = true
+		if in.colorMap == nil {
+			return nil, fmt.Errorf("could not pack %s: no color map", in.name)
+		}
+		if in.normalMap != nil && in.normalMap.Bounds().Size() != in.colorMap.Bounds().Size() {
+			return nil, fmt.Errorf("could not pack %s: normal map is not the size of the color map", in.name)
+		}
+		rects[i] = in.colorMap.Bounds().Size().Add(image.Pt(padding*2, padding*2))
+	}
+
+	pages, places, err := pack(rects, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	a := Atlas{
+		Regions: make(map[string]Region, len(b.inputs)),
+		padding: padding,
+	}
+	hasNormals := make([]bool, len(pages))
+	for i, in := range b.inputs {
+		if in.normalMap != nil {
+			hasNormals[places[i].page] = true
+		}
+	}
+	for i, size := range pages {
+		p := Page{
+			ColorMap: image.NewRGBA(image.Rectangle{Max: size}),
+		}
+		if hasNormals[i] {
+			p.NormalMap = image.NewRGBA(image.Rectangle{Max: size})
+		}
+		a.Pages = append(a.Pages, &p)
+	}
+
+	for i, in := range b.inputs {
+		pl := places[i]
+		bounds := image.Rectangle{Min: pl.pos, Max: pl.pos.Add(rects[i])}.Inset(padding)
+		page := a.Pages[pl.page]
+		blit(page.ColorMap, bounds, in.colorMap, padding)
+		if page.NormalMap != nil {
+			var normal image.Image = in.normalMap
+			if normal == nil {
+				normal = &image.Uniform{flatNormal}
+			}
+			blit(page.NormalMap, bounds, normal, padding)
+		}
+		a.Regions[in.name] = Region{
+			Name:    in.name,
+			Page:    pl.page,
+			Bounds:  bounds,
+			FramesX: in.framesX,
+			FramesY: in.framesY,
+		}
+	}
+	return &a, nil
+}
+
+// Sprite returns the region name as a sprite, see sprite.Context.Region.  Sprites
+// of the same page share its textures, which are uploaded when the first of them is
+// bound.
+func (a *Atlas) Sprite(name string) (*sprite.Context, error) {
+	r, ok := a.Regions[name]
+	if !ok {
+		return nil, fmt.Errorf("atlas has no region %s", name)
+	}
+	p := a.Pages[r.Page]
+	if p.sprite == nil {
+		var normalMap image.Image
+		if p.NormalMap != nil {
+			normalMap = p.NormalMap
+		}
+		s, err := sprite.New(p.ColorMap, normalMap, 1, 1)
+		if err != nil {
+			return nil, err
+		}
+		s.Sampling = a.Sampling
+		if s.Sampling.Mipmaps {
+			levels := mipLevels(a.padding)
+			if levels == 0 {
+				fmt.Println("Warning: atlas regions are not padded enough for mipmaps, disabling them")
+				s.Sampling.Mipmaps = false
+			} else if s.Sampling.MipLevels == 0 || s.Sampling.MipLevels > levels {
+				s.Sampling.MipLevels = levels
+			}
+		}
+		p.sprite = s
+	}
+	return p.sprite.Region(r.Bounds, r.FramesX, r.FramesY)
+}
+
+// mipLevels which padding pixels keep regions from bleeding into each other at.
+func mipLevels(padding int) int {
+	n := 0
+	for p := padding; p > 1; p /= 2 {
+		n++
+	}
+	return n
+}
+
+// Release the OpenGL objects of every page.
+func (a *Atlas) Release() {
+	for _, p := range a.Pages {
+		if p.sprite != nil {
+			p.sprite.Release()
+		}
+	}
+}
+
+// blit src into r of dst, repeating its edge pixels padding pixels out from r.
+func blit(dst *image.RGBA, r image.Rectangle, src image.Image, padding int) {
+	draw.Draw(dst, r, src, src.Bounds().Min, draw.Src)
+	if padding == 0 {
+		return
+	}
+
+	// Extrude the edges, then the corners come along with the top and bottom rows.
+	for i := 1; i <= padding; i++ {
+		draw.Draw(dst, image.Rect(r.Min.X-i, r.Min.Y, r.Min.X-i+1, r.Max.Y), dst, image.Pt(r.Min.X, r.Min.Y), draw.Src)
+		draw.Draw(dst, image.Rect(r.Max.X+i-1, r.Min.Y, r.Max.X+i, r.Max.Y), dst, image.Pt(r.Max.X-1, r.Min.Y), draw.Src)
+	}
+	x0, x1 := r.Min.X-padding, r.Max.X+padding
+	for i := 1; i <= padding; i++ {
+		draw.Draw(dst, image.Rect(x0, r.Min.Y-i, x1, r.Min.Y-i+1), dst, image.Pt(x0, r.Min.Y), draw.Src)
+		draw.Draw(dst, image.Rect(x0, r.Max.Y+i-1, x1, r.Max.Y+i), dst, image.Pt(x0, r.Max.Y-1), draw.Src)
+	}
+}
+
+// place of a rectangle packed by pack.
+type place struct {
+	page int
+	pos  image.Point
+}
+
+// shelf is a row of rectangles on a page.
+type shelf struct {
+	y      int
+	height int
+	width  int
+}
+
+// pack rects into pages no larger than maxSize, using first fit on shelves of
+// rectangles sorted by height.  It returns the size of each page and where each
+// rectangle was placed.
+func pack(rects []image.Point, maxSize int) ([]image.Point, []place, error) {
+	order := make([]int, len(rects))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Stable(byHeight{order, rects})
+
+	var pages []image.Point
+	var shelves [][]shelf
+	places := make([]place, len(rects))
+	for _, i := range order {
+		r := rects[i]
+		if r.X > maxSize || r.Y > maxSize {
+			return nil, nil, fmt.Errorf("could not pack a %dx%d image into pages of %dx%d", r.X, r.Y, maxSize, maxSize)
+		}
+		placed := false
+		for p := 0; p < len(pages) && !placed; p++ {
+			for s := range shelves[p] {
+				sh := &shelves[p][s]
+				if r.Y <= sh.height && sh.width+r.X <= maxSize {
+					places[i] = place{p, image.Pt(sh.width, sh.y)}
+					sh.width += r.X
+					placed = true
+					break
+				}
+			}
+			if placed {
+				break
+			}
+			// Start a new shelf under the last one.
+			y := 0
+			if n := len(shelves[p]); n > 0 {
+				y = shelves[p][n-1].y + shelves[p][n-1].height
+			}
+			if y+r.Y <= maxSize {
+				shelves[p] = append(shelves[p], shelf{y: y, height: r.Y, width: r.X})
+				places[i] = place{p, image.Pt(0, y)}
+				placed = true
+			}
+		}
+		if !placed {
+			pages = append(pages, image.Point{})
+			shelves = append(shelves, []shelf{{y: 0, height: r.Y, width: r.X}})
+			places[i] = place{len(pages) - 1, image.Pt(0, 0)}
+		}
+		p := places[i].page
+		if x := places[i].pos.X + r.X; x > pages[p].X {
+			pages[p].X = x
+		}
+		if y := places[i].pos.Y + r.Y; y > pages[p].Y {
+			pages[p].Y = y
+		}
+	}
+	return pages, places, nil
+}
+
+// byHeight sorts indexes into rects by height, then width, tallest first.
+type byHeight struct {
+	order []int
+	rects []image.Point
+}
+
+func (a byHeight) Len() int      { return len(a.order) }
+func (a byHeight) Swap(i, j int) { a.order[i], a.order[j] = a.order[j], a.order[i] }
+func (a byHeight) Less(i, j int) bool {
+	ri, rj := a.rects[a.order[i]], a.rects[a.order[j]]
+	if ri.Y != rj.Y {
+		return ri.Y > rj.Y
+	}
+	return ri.X > rj.X
+}
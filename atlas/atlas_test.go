@@ -0,0 +1,150 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atlas
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func solid(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestBuildNoOverlap(t *testing.T) {
+	b := Builder{MaxSize: 128, Padding: 1}
+	sizes := []image.Point{{30, 30}, {60, 10}, {10, 60}, {20, 20}, {50, 40}, {8, 8}, {100, 20}}
+	names := []string{"a", "b", "c", "d", "e", "f", "g"}
+	for i, s := range sizes {
+		b.Add(names[i], solid(s.X, s.Y, color.RGBA{uint8(i * 30), 0, 0, 255}), nil, 1, 1)
+	}
+
+	a, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, name := range names {
+		r, ok := a.Regions[name]
+		if !ok {
+			t.Fatal("Expected region", name)
+		}
+		if r.Bounds.Size() != sizes[i] {
+			t.Error("Expected region", name, "to be", sizes[i], "but found", r.Bounds.Size())
+		}
+		page := a.Pages[r.Page].ColorMap
+		if !r.Bounds.Inset(-1).In(page.Bounds()) {
+			t.Error("Expected padded region", name, r.Bounds, "to be inside page", page.Bounds())
+		}
+		for _, other := range names[i+1:] {
+			o := a.Regions[other]
+			if o.Page == r.Page && o.Bounds.Inset(-1).Overlaps(r.Bounds.Inset(-1)) {
+				t.Error("Expected regions", name, r.Bounds, "and", other, o.Bounds, "not to overlap")
+			}
+		}
+		if got := page.RGBAAt(r.Bounds.Min.X, r.Bounds.Min.Y); got.R != uint8(i*30) {
+			t.Error("Expected region", name, "to hold its image but found", got)
+		}
+	}
+}
+
+func TestBuildPages(t *testing.T) {
+	b := Builder{MaxSize: 64, Padding: -1}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		b.Add(name, solid(32, 64, color.RGBA{255, 255, 255, 255}), nil, 1, 1)
+	}
+
+	a, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Pages) != 3 {
+		t.Error("Expected 3 pages but found", len(a.Pages))
+	}
+	if size := a.Pages[2].ColorMap.Bounds().Size(); size != image.Pt(32, 64) {
+		t.Error("Expected the last page to be trimmed to 32x64 but found", size)
+	}
+}
+
+func TestBuildPadding(t *testing.T) {
+	b := Builder{Padding: 2}
+	img := solid(4, 4, color.RGBA{0, 0, 255, 255})
+	img.SetRGBA(0, 0, color.RGBA{255, 0, 0, 255})
+	b.Add("a", img, nil, 1, 1)
+	b.Add("b", solid(4, 4, color.RGBA{0, 255, 0, 255}), solid(4, 4, color.RGBA{1, 2, 3, 255}), 1, 1)
+
+	a, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := a.Regions["a"].Bounds
+	page := a.Pages[0]
+	if got := page.ColorMap.RGBAAt(r.Min.X-2, r.Min.Y-2); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Error("Expected the corner to be extruded into the padding but found", got)
+	}
+	if got := page.ColorMap.RGBAAt(r.Max.X+1, r.Min.Y); got != (color.RGBA{0, 0, 255, 255}) {
+		t.Error("Expected the right edge to be extruded into the padding but found", got)
+	}
+	if page.NormalMap == nil {
+		t.Fatal("Expected a normal map since b has one")
+	}
+	if got := page.NormalMap.RGBAAt(r.Min.X, r.Min.Y); got != flatNormal {
+		t.Error("Expected a flat normal for a but found", got)
+	}
+	rb := a.Regions["b"].Bounds
+	if got := page.NormalMap.RGBAAt(rb.Min.X, rb.Min.Y); got != (color.RGBA{1, 2, 3, 255}) {
+		t.Error("Expected b's normal map but found", got)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	b := Builder{MaxSize: 16}
+	b.Add("big", solid(16, 16, color.RGBA{}), nil, 1, 1)
+	if _, err := b.Build(); err == nil {
+		t.Error("Expected an error for an image which does not fit with padding")
+	}
+
+	b = Builder{}
+	b.Add("a", solid(4, 4, color.RGBA{}), nil, 1, 1)
+	b.Add("a", solid(4, 4, color.RGBA{}), nil, 1, 1)
+	if _, err := b.Build(); err == nil {
+		t.Error("Expected an error for a duplicate name")
+	}
+}
+
+func TestMipLevels(t *testing.T) {
+	tests := []struct {
+		padding int
+		levels  int
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 1},
+		{3, 1},
+		{4, 2},
+		{16, 4},
+	}
+	for _, tt := range tests {
+		if got := mipLevels(tt.padding); got != tt.levels {
+			t.Error("Expected", tt.levels, "levels for padding", tt.padding, "but found", got)
+		}
+	}
+}
@@ -19,7 +19,9 @@ package camera
 import (
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade/display"
 	"github.com/hurricanerix/shade/entity"
+	"github.com/hurricanerix/shade/shader"
 )
 
 // Context contains the camera's state
@@ -54,34 +56,79 @@ type Context struct {
 	// ViewMatrix for the current camera's position
 	ViewMatrix mgl32.Mat4
 
-	// viewMatrixLoc in GLSL program
-	viewMatrixLoc int32
+	// programs the camera is bound to
+	programs []*shader.Program
+	// screen the camera is resized with
+	screen *display.Context
 }
 
-// New camera is returned.
-func New() (*Context, error) {
+// New camera is returned.  It is the size of screen, which is its virtual resolution
+// if it has one, and is resized with the screen's window.
+func New(screen *display.Context) (*Context, error) {
 	c := Context{
 		Pos:    mgl32.Vec3{},
-		Width:  640,
-		Height: 480,
+		Width:  screen.Width,
+		Height: screen.Height,
+		screen: screen,
 	}
+	screen.AddResizer(&c)
 	return &c, nil
 }
 
-// Bind the camera to OpenGL
+// Release the camera so it is no longer resized with its screen or uploaded to the
+// programs it was bound to.
+func (c *Context) Release() {
+	c.screen.RemoveResizer(c)
+	c.programs = nil
+}
+
+// Bind the camera to OpenGL.  The camera may be bound to more than one program, in
+// which case all of them are updated when it moves.
 func (c *Context) Bind(program uint32) {
+	p := shader.Lookup(program)
+	bound := false
+	for i := range c.programs {
+		bound = bound || c.programs[i] == p
+	}
+	if bound {
+		c.upload()
+		return
+	}
+	c.programs = append(c.programs, p)
+	p.OnReload(c.upload)
+	c.project()
+	if len(c.programs) == 1 {
+		c.Move(mgl32.Vec3{})
+	}
+}
+
+// upload the camera's matrices to every program it is bound to.
+func (c *Context) upload() {
+	for _, p := range c.programs {
+		p.Use()
+		gl.UniformMatrix4fv(p.Uniform("ProjMatrix"), 1, false, &c.ProjMatrix[0])
+		gl.UniformMatrix4fv(p.Uniform("ViewMatrix"), 1, false, &c.ViewMatrix[0])
+	}
+}
+
+// Resize the camera to width x height pixels.  Cameras are resized with the screen
+// they were created for, see display.Context.AddResizer.
+func (c *Context) Resize(width, height float32) {
+	c.Width = width
+	c.Height = height
+	c.Right = c.Left + c.Width
+	c.Top = c.Bottom + c.Height
+	c.project()
+}
+
+func (c *Context) project() {
 	var left, right, top, bottom, near, far float32
 	right = float32(c.Width)
 	top = float32(c.Height)
 	near = 0.1
 	far = 100.0
 	c.ProjMatrix = mgl32.Ortho(left, right, bottom, top, near, far)
-	projUniform := gl.GetUniformLocation(program, gl.Str("ProjMatrix\x00"))
-	gl.UniformMatrix4fv(projUniform, 1, false, &c.ProjMatrix[0])
-
-	c.viewMatrixLoc = gl.GetUniformLocation(program, gl.Str("ViewMatrix\x00"))
-	c.Move(mgl32.Vec3{})
-	gl.UniformMatrix4fv(c.viewMatrixLoc, 1, false, &c.ViewMatrix[0])
+	c.upload()
 }
 
 // Move the camera to pos, unless that position conflicts with stops, in which case
@@ -114,7 +161,7 @@ func (c *Context) Move(pos mgl32.Vec3) {
 	up = mgl32.Vec3{0.0, 1.0, 0.0}
 	c.ViewMatrix = mgl32.LookAtV(eye, center, up)
 
-	gl.UniformMatrix4fv(c.viewMatrixLoc, 1, false, &c.ViewMatrix[0])
+	c.upload()
 }
 
 // Follow the pos uing a simple linear interpolation algorithm.  How fast the camera
@@ -150,7 +197,7 @@ func (c *Context) Follow(pos mgl32.Vec3, lerp float32) {
 	up = mgl32.Vec3{0.0, 1.0, 0.0}
 	c.ViewMatrix = mgl32.LookAtV(eye, center, up)
 
-	gl.UniformMatrix4fv(c.viewMatrixLoc, 1, false, &c.ViewMatrix[0])
+	c.upload()
 }
 
 // TODO: refactor entity interface so Update can be removed for cameras.
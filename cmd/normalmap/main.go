@@ -0,0 +1,103 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command normalmap generates a normal map for each image given, saving it next to
+// the image with ".normal" before the extension as the sprite packages expect.
+//
+//	normalmap [-strength 2] [-blur 0] [-invert] [-o output.png] image.png...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hurricanerix/shade/normalmap"
+)
+
+var (
+	strength float64
+	blur     int
+	invert   bool
+	output   string
+)
+
+func init() {
+	flag.Float64Var(&strength, "strength", normalmap.DefaultStrength, "how pronounced the bumps are.")
+	flag.IntVar(&blur, "blur", 0, "blur radius of the height map in pixels.")
+	flag.BoolVar(&invert, "invert", false, "darker pixels are higher.")
+	flag.StringVar(&output, "o", "", "output file, only when a single image is given.")
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: normalmap [flags] image...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || (output != "" && flag.NArg() > 1) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts := normalmap.Options{
+		Strength: float32(strength),
+		Blur:     blur,
+		Invert:   invert,
+	}
+	for _, path := range flag.Args() {
+		out := output
+		if out == "" {
+			out = normalName(path)
+		}
+		if err := generate(path, out, opts); err != nil {
+			log.Fatalln("failed to generate normal map:", err)
+		}
+		fmt.Println("Wrote", out)
+	}
+}
+
+// normalName of the normal map for the image at path, which is always a PNG.
+func normalName(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".normal.png"
+}
+
+func generate(path, out string, opts normalmap.Options) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open file %s: %v", path, err)
+	}
+	defer f.Close()
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return fmt.Errorf("could not decode file %s: %v", path, err)
+	}
+
+	w, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(w, normalmap.Generate(src, opts)); err != nil {
+		w.Close()
+		return fmt.Errorf("could not encode file %s: %v", out, err)
+	}
+	return w.Close()
+}
@@ -0,0 +1,210 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// MaxGIFFrames recorded to a GIF, further frames are dropped.  A GIF is written
+// when recording stops, so every frame is kept in memory until then, about
+// width * height bytes each.
+var MaxGIFFrames = 300
+
+// recording of frames, see StartRecording.
+type recording struct {
+	path  string
+	every int
+	count int
+	saved int
+	last  time.Time
+	gif   bool
+	// full is set once MaxGIFFrames have been recorded
+	full bool
+	// delays of the GIF's frames in 100ths of a second
+	delays []int
+	// frames are saved or quantized by save, which closes done when frames is
+	// closed and it has finished
+	frames chan *image.RGBA
+	done   chan struct{}
+	images []*image.Paletted
+	err    error
+}
+
+// save the recorded frames as they arrive, off the render path.
+func (r *recording) save() {
+	defer close(r.done)
+	n := 0
+	for img := range r.frames {
+		if r.err != nil {
+			continue
+		}
+		if r.gif {
+			frame := image.NewPaletted(img.Bounds(), palette.Plan9)
+			draw.FloydSteinberg.Draw(frame, img.Bounds(), img, image.Point{})
+			r.images = append(r.images, frame)
+			continue
+		}
+		path := filepath.Join(r.path, fmt.Sprintf("frame%05d.png", n))
+		if err := SavePNG(path, img); err != nil {
+			r.err = fmt.Errorf("could not save recorded frame: %v", err)
+		}
+		n++
+	}
+}
+
+// Screenshot saves the next frame shown by Flip, including any post processing, to
+// path as a PNG.  Errors saving it are returned by CaptureErr.
+func (c *Context) Screenshot(path string) {
+	c.screenshots = append(c.screenshots, path)
+}
+
+// CaptureErr returns the first error saving a screenshot since it was last
+// called.
+func (c *Context) CaptureErr() error {
+	err := c.captureErr
+	c.captureErr = nil
+	return err
+}
+
+// StartRecording captures every Nth frame shown by Flip until StopRecording is
+// called.  If path ends in ".gif" the frames are saved as an animated GIF of up to
+// MaxGIFFrames frames when recording stops, otherwise path is a directory the
+// frames are saved to as a sequence of PNGs named frame00000.png, frame00001.png,
+// and so on.  Frames are saved in the background so recording slows Flip down
+// as little as possible.
+func (c *Context) StartRecording(path string, every int) error {
+	if c.recording != nil {
+		return fmt.Errorf("already recording to %s", c.recording.path)
+	}
+	if every < 1 {
+		every = 1
+	}
+	r := recording{
+		path:   path,
+		every:  every,
+		gif:    strings.EqualFold(filepath.Ext(path), ".gif"),
+		frames: make(chan *image.RGBA, 4),
+		done:   make(chan struct{}),
+	}
+	if !r.gif {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return fmt.Errorf("could not create recording directory: %v", err)
+		}
+	}
+	go r.save()
+	c.recording = &r
+	return nil
+}
+
+// StopRecording stops capturing frames and waits for them to be saved, writing
+// the GIF if recording to one.  The first error saving a frame is returned.
+func (c *Context) StopRecording() error {
+	r := c.recording
+	if r == nil {
+		return fmt.Errorf("not recording")
+	}
+	c.recording = nil
+	close(r.frames)
+	<-r.done
+	if r.err != nil || !r.gif {
+		return r.err
+	}
+
+	f, err := os.Create(r.path)
+	if err != nil {
+		return err
+	}
+	anim := gif.GIF{
+		Image: r.images,
+		Delay: r.delays,
+	}
+	if err := gif.EncodeAll(f, &anim); err != nil {
+		f.Close()
+		return fmt.Errorf("could not encode file %s: %v", r.path, err)
+	}
+	return f.Close()
+}
+
+// capture the frame about to be shown if a screenshot or recording needs it.
+func (c *Context) capture() {
+	r := c.recording
+	record := r != nil && r.count%r.every == 0
+	if r != nil {
+		r.count++
+	}
+	if record && r.gif && r.saved >= MaxGIFFrames {
+		if !r.full {
+			fmt.Println("Warning: recorded", MaxGIFFrames, "frames, dropping the rest of", r.path)
+			r.full = true
+		}
+		record = false
+	}
+	if !record && len(c.screenshots) == 0 {
+		return
+	}
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, c.framebuffer)
+	img := readPixels(c.viewport[0], c.viewport[1], c.viewport[2], c.viewport[3])
+
+	for _, path := range c.screenshots {
+		if err := SavePNG(path, img); err != nil && c.captureErr == nil {
+			c.captureErr = fmt.Errorf("could not save screenshot: %v", err)
+		}
+	}
+	c.screenshots = nil
+
+	if !record {
+		return
+	}
+	if r.gif {
+		now := time.Now()
+		if n := len(r.delays); n > 0 {
+			// GIF delays are in 100ths of a second.
+			r.delays[n-1] = int(now.Sub(r.last) / (10 * time.Millisecond))
+		}
+		r.last = now
+		r.delays = append(r.delays, r.every*100/60)
+	}
+	r.frames <- img
+	r.saved++
+}
+
+// SavePNG to path, creating any missing directories.
+func SavePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("could not encode file %s: %v", path, err)
+	}
+	return f.Close()
+}
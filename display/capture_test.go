@@ -0,0 +1,175 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display_test
+
+import (
+	"fmt"
+	"image/color"
+	"image/gif"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hurricanerix/shade/camera"
+	"github.com/hurricanerix/shade/display"
+	"github.com/hurricanerix/shade/shadetest"
+)
+
+// captureScene starts capturing in Setup, before any frame is flipped.
+type captureScene struct {
+	screenshot string
+	record     string
+	screen     *display.Context
+	err        error
+}
+
+func (s *captureScene) Setup(screen *display.Context, cam *camera.Context) error {
+	s.screen = screen
+	if s.screenshot != "" {
+		screen.Screenshot(s.screenshot)
+	}
+	if s.record != "" {
+		s.err = screen.StartRecording(s.record, 1)
+	}
+	return nil
+}
+
+func (s *captureScene) Frame(n int, dt float32) {}
+
+// captureOptions render small frames with a red background.
+var captureOptions = shadetest.Options{Width: 32, Height: 16, Frames: 3, Background: [3]float32{1, 0, 0}}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "capture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestScreenshot(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		path string
+		err  bool
+	}{
+		{filepath.Join(dir, "shot.png"), false},
+		// The parent of the screenshot is a file.
+		{filepath.Join(dir, "shot.png", "shot.png"), true},
+	}
+	for _, tt := range tests {
+		s := captureScene{screenshot: tt.path}
+		if _, err := shadetest.Render(&s, captureOptions); err != nil {
+			t.Skip(err)
+		}
+		var err error
+		shadetest.Do(func() { err = s.screen.CaptureErr() })
+		if (err != nil) != tt.err {
+			t.Error("Expected error", tt.err, "but found", err)
+		}
+		if tt.err {
+			continue
+		}
+		img, err := shadetest.LoadPNG(tt.path)
+		if err != nil {
+			t.Error("Expected a screenshot but found", err)
+			continue
+		}
+		if c := color.RGBAModel.Convert(img.At(0, 0)); c != (color.RGBA{255, 0, 0, 255}) {
+			t.Error("Expected a red screenshot but found", c)
+		}
+	}
+}
+
+func TestRecording(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	s := captureScene{record: filepath.Join(dir, "frames")}
+	if _, err := shadetest.Render(&s, captureOptions); err != nil {
+		t.Skip(err)
+	}
+	if s.err != nil {
+		t.Fatal("Expected recording to start but found", s.err)
+	}
+	var again, stop, stopped error
+	shadetest.Do(func() {
+		again = s.screen.StartRecording(s.record, 1)
+		stop = s.screen.StopRecording()
+		stopped = s.screen.StopRecording()
+	})
+	if again == nil {
+		t.Error("Expected an error starting a second recording but found nil")
+	}
+	if stop != nil {
+		t.Error("Expected recording to stop but found", stop)
+	}
+	if stopped == nil {
+		t.Error("Expected an error stopping again but found nil")
+	}
+	// Render flips each of its 3 frames.
+	for i := 0; i < 3; i++ {
+		path := filepath.Join(s.record, fmt.Sprintf("frame%05d.png", i))
+		if _, err := os.Stat(path); err != nil {
+			t.Error("Expected recorded frame", path, "but found", err)
+		}
+	}
+}
+
+func TestRecordingGIF(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	defer func(max int) {
+		display.MaxGIFFrames = max
+	}(display.MaxGIFFrames)
+
+	tests := []struct {
+		max    int
+		frames int
+	}{
+		{10, 3},
+		{2, 2},
+	}
+	for i, tt := range tests {
+		display.MaxGIFFrames = tt.max
+		s := captureScene{record: filepath.Join(dir, fmt.Sprintf("anim%d.gif", i))}
+		if _, err := shadetest.Render(&s, captureOptions); err != nil {
+			t.Skip(err)
+		}
+		var err error
+		shadetest.Do(func() { err = s.screen.StopRecording() })
+		if err != nil {
+			t.Error("Expected recording to stop but found", err)
+			continue
+		}
+		f, err := os.Open(s.record)
+		if err != nil {
+			t.Error("Expected a GIF but found", err)
+			continue
+		}
+		anim, err := gif.DecodeAll(f)
+		f.Close()
+		if err != nil {
+			t.Error("Expected a GIF but found", err)
+			continue
+		}
+		if len(anim.Image) != tt.frames || len(anim.Delay) != tt.frames {
+			t.Error("Expected", tt.frames, "frames but found", len(anim.Image), "with", len(anim.Delay), "delays")
+		}
+	}
+}
@@ -17,12 +17,15 @@ package display
 
 import (
 	"fmt"
+	"os"
 	"runtime"
-	"strings"
+	"time"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/hurricanerix/shade/events"
+	"github.com/hurricanerix/shade/gpu"
+	"github.com/hurricanerix/shade/shader"
 )
 
 func init() {
@@ -32,10 +35,78 @@ func init() {
 
 // Context TODO doc
 type Context struct {
-	Window  *glfw.Window
-	Width   float32
+	Window *glfw.Window
+	// Width of the screen in pixels, or the virtual width if a virtual resolution is used.
+	Width float32
+	// Height of the screen in pixels, or the virtual height if a virtual resolution is used.
 	Height  float32
 	Program uint32
+	// Shader is the default program, Program is its ID.
+	Shader *shader.Program
+	// Version of OpenGL that was selected when creating the display.
+	Version Version
+	// ShaderErr is the last error from reloading shaders, see WatchShaders.
+	ShaderErr error
+	// Offscreen is true if rendering goes to a framebuffer object instead of the window.
+	Offscreen bool
+	// Mode the window is currently displayed in.
+	Mode WindowMode
+	// options the display was created with
+	options Options
+	// watch for shader changes, nil unless WatchShaders was called
+	watch *shaderWatch
+	// viewport the scene is drawn to in framebuffer pixels (x, y, width, height)
+	viewport [4]int32
+	// windowed position and size, restored when leaving full screen
+	windowed [4]int
+	// passes applied to each frame, see SetPostProcess
+	passes    []*Pass
+	postStart time.Time
+	postVAO   uint32
+	postVBO   uint32
+	// scene is drawn to when post processing, pingpong holds intermediate passes
+	scene    *RenderTarget
+	pingpong [2]*RenderTarget
+	// framebuffer the scene is rendered to when Offscreen is true
+	framebuffer uint32
+	colorbuffer uint32
+	depthbuffer uint32
+	// screenshots and recording waiting for the next Flip, see capture.go
+	screenshots []string
+	captureErr  error
+	recording   *recording
+	// flushers drawn at the start of Flip, see AddFlusher
+	flushers []Flusher
+	// resizers resized with the window, see AddResizer
+	resizers []Resizer
+}
+
+// Version of OpenGL a Context was created with, and of the GLSL its shaders use.
+type Version struct {
+	Major int
+	Minor int
+	// GLSL version of the Context's shaders, 120, 330 or 410.
+	GLSL int
+}
+
+// AtLeast returns true if v is OpenGL major.minor or newer.
+func (v Version) AtLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("OpenGL %d.%d (GLSL %d)", v.Major, v.Minor, v.GLSL)
+}
+
+// supportedVersions are tried newest first until a context and program are created.
+var supportedVersions = []struct {
+	Version
+	vertexShader   string
+	fragmentShader string
+}{
+	{Version{4, 1, 410}, "#version 410 core\n" + coreVertexShader, "#version 410 core\n" + coreFragmentShader},
+	{Version{3, 3, 330}, "#version 330 core\n" + coreVertexShader, "#version 330 core\n" + coreFragmentShader},
+	{Version{2, 1, 120}, vertexShader120, fragmentShader120},
 }
 
 // Signal to close the window
@@ -44,40 +115,148 @@ func (c *Context) Close() {
 	events.WindowCloseCallback(c.Window)
 }
 
-func createWindow(major, minor int, title string) (*glfw.Window, error) {
-	glfw.WindowHint(glfw.Resizable, glfw.False)
+// Release the display's OpenGL objects, which are its shader program, offscreen
+// framebuffer and post processing targets.  Passes given to SetPostProcess are
+// owned by the caller, see Pass.Delete.
+func (c *Context) Release() {
+	c.passes = nil
+	c.deleteTargets()
+	c.deleteQuad()
+	c.deleteFramebuffer()
+	if c.Shader != nil {
+		c.Shader.Delete()
+	}
+	c.Program = 0
+}
+
+// Terminate releases the display, destroys its window and terminates GLFW.  No
+// other display may be used afterwards.  With Options.Debug, OpenGL objects which
+// are still alive are reported.
+func (c *Context) Terminate() {
+	if c.recording != nil {
+		if err := c.StopRecording(); err != nil {
+			fmt.Println("Warning:", err)
+		}
+	}
+	c.Release()
+	if gpu.Debug {
+		if n := gpu.Report(os.Stdout); n > 0 {
+			fmt.Println("Warning:", n, "OpenGL objects were not released")
+		}
+	}
+	if c.Window != nil {
+		c.Window.Destroy()
+		c.Window = nil
+	}
+	glfw.Terminate()
+}
+
+func createWindow(major, minor int, title string, width, height int, opts Options) (*glfw.Window, error) {
+	glfw.DefaultWindowHints()
+	if opts.Resizable {
+		glfw.WindowHint(glfw.Resizable, glfw.True)
+	} else {
+		glfw.WindowHint(glfw.Resizable, glfw.False)
+	}
+	if opts.Offscreen {
+		glfw.WindowHint(glfw.Visible, glfw.False)
+	} else {
+		glfw.WindowHint(glfw.Visible, glfw.True)
+	}
 	glfw.WindowHint(glfw.ContextVersionMajor, major)
 	glfw.WindowHint(glfw.ContextVersionMinor, minor)
-	if major != 2 && minor != 1 {
+	if major > 3 || (major == 3 && minor >= 2) {
 		glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
 		glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
 	}
-	return glfw.CreateWindow(640, 480, title, nil, nil)
+
+	if opts.Offscreen || opts.Mode == Windowed {
+		return glfw.CreateWindow(width, height, title, nil, nil)
+	}
+
+	monitor, err := getMonitor(opts.Monitor)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Mode == Borderless {
+		// Matching the desktop's video mode gives a "windowed full screen" window.
+		mode := monitor.GetVideoMode()
+		glfw.WindowHint(glfw.RedBits, mode.RedBits)
+		glfw.WindowHint(glfw.GreenBits, mode.GreenBits)
+		glfw.WindowHint(glfw.BlueBits, mode.BlueBits)
+		glfw.WindowHint(glfw.RefreshRate, mode.RefreshRate)
+		width, height = mode.Width, mode.Height
+	}
+	return glfw.CreateWindow(width, height, title, monitor, nil)
+}
+
+// createContext for OpenGL version v, making it current and loading the shaders
+// into c.Program.  If anything fails the window is destroyed.
+func (c *Context) createContext(v Version, vertexShader, fragmentShader, title string, width, height int, opts Options) error {
+	window, err := createWindow(v.Major, v.Minor, title, width, height, opts)
+	if err != nil {
+		return err
+	}
+	window.MakeContextCurrent()
+
+	if err := gl.Init(); err != nil {
+		window.Destroy()
+		return fmt.Errorf("failed to init glow: %v", err)
+	}
+
+	program, err := shader.New(vertexShader, fragmentShader)
+	if err != nil {
+		window.Destroy()
+		return fmt.Errorf("error loading program for %v: %v", v, err)
+	}
+
+	c.Window = window
+	c.Version = v
+	c.Shader = program
+	c.Program = program.ID
+	return nil
 }
 
 // SetMode TODO doc
 func SetMode(title string, width, height int) (*Context, error) {
+	return SetModeOptions(title, width, height, Options{})
+}
+
+// SetModeOffscreen returns a Context which renders into a width x height framebuffer
+// object attached to a hidden window, so nothing is ever shown on screen.  This
+// allows rendering code to run in CI, for example with Mesa's software rasterizer:
+//
+//	LIBGL_ALWAYS_SOFTWARE=1 xvfb-run go test ./...
+//
+// Use ReadPixels to get the contents of the rendered frame.
+func SetModeOffscreen(title string, width, height int) (*Context, error) {
+	return SetModeOptions(title, width, height, Options{Offscreen: true})
+}
+
+// SetModeOptions is like SetMode, but the window is created as described by opts.
+func SetModeOptions(title string, width, height int, opts Options) (*Context, error) {
 	c := Context{
-		Width:  float32(width),
-		Height: float32(height),
+		Width:     float32(width),
+		Height:    float32(height),
+		Offscreen: opts.Offscreen,
+		Mode:      opts.Mode,
+		options:   opts,
+		windowed:  [4]int{-1, -1, width, height},
+	}
+	if opts.VirtualWidth > 0 && opts.VirtualHeight > 0 {
+		c.Width = float32(opts.VirtualWidth)
+		c.Height = float32(opts.VirtualHeight)
+	}
+	if opts.Debug {
+		gpu.Debug = true
 	}
 	if err := glfw.Init(); err != nil {
 		return &c, fmt.Errorf("failed to initialize glfw: %v", err)
 	}
-	// TODO: move this to a terminate function
-	//defer glfw.Terminate()
-
-	supportedVersions := [][2]int{
-		// TODO: create matching shaders for these versions
-		//[2]int{4, 1},
-		//[2]int{3, 3},
-		[2]int{2, 1},
-	}
 
 	var err error
-	var window *glfw.Window
 	for _, v := range supportedVersions {
-		window, err = createWindow(v[0], v[1], title)
+		err = c.createContext(v.Version, v.vertexShader, v.fragmentShader, title, width, height, opts)
 		if err == nil {
 			// Successfully created window, break out of loop
 			break
@@ -86,39 +265,42 @@ func SetMode(title string, width, height int) (*Context, error) {
 		fmt.Println("Warning:", err)
 	}
 
-	c.Window = window
+	if c.Window == nil {
+		return &c, fmt.Errorf("failed to create window: %v", err)
+	}
 
-	c.Window.MakeContextCurrent()
 	c.Window.SetKeyCallback(events.KeyCallback)
 	c.Window.SetMouseButtonCallback(events.MouseButtonCallback)
 	c.Window.SetCursorPosCallback(events.CursorPositionCallback)
 	c.Window.SetCloseCallback(events.WindowCloseCallback)
-
-	if err := gl.Init(); err != nil {
-		return &c, fmt.Errorf("failed to init glow: %v", err)
-	}
+	c.Window.SetFramebufferSizeCallback(c.framebufferSizeCallback)
 
 	fmt.Println("OpenGL vendor", gl.GoStr(gl.GetString(gl.VENDOR)))
 	fmt.Println("OpenGL renderer", gl.GoStr(gl.GetString(gl.RENDERER)))
 	fmt.Println("OpenGL version", gl.GoStr(gl.GetString(gl.VERSION)))
 	fmt.Println("GLSL version", gl.GoStr(gl.GetString(gl.SHADING_LANGUAGE_VERSION)))
+	fmt.Println("Using", c.Version)
 
-	c.Program, err = newProgram(vertexShader, fragmentShader)
-	if err != nil {
-		return &c, fmt.Errorf("error loading program: %v", err)
+	if c.Offscreen {
+		if err := c.createFramebuffer(width, height); err != nil {
+			return &c, err
+		}
+	} else {
+		c.resize(c.Window.GetFramebufferSize())
 	}
 
 	gl.UseProgram(c.Program)
 
-	gl.BindFragDataLocation(c.Program, 0, gl.Str("FragColor\x00"))
-
 	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
 	gl.Enable(gl.BLEND)
-	// TODO: Figure out why "layering" using z-buffer does not work.
-	// gl.DepthFunc(gl.LESS)
-	// gl.Enable(gl.DEPTH_TEST)
-	gl.DepthFunc(gl.NEVER)
-	gl.Enable(gl.CULL_FACE)
+	// Sprites with a higher Z are drawn on top.  LEQUAL lets sprites with the same Z
+	// layer by draw order, and fully transparent pixels are discarded by the shaders
+	// so they don't hide what is drawn behind them later.  Translucent sprites still
+	// need to be drawn back to front, see entity.Draw.
+	gl.DepthFunc(gl.LEQUAL)
+	gl.Enable(gl.DEPTH_TEST)
+	// Faces are not culled, flipped sprites are drawn with their winding reversed.
+	gl.Disable(gl.CULL_FACE)
 	gl.ClearColor(0.0, 0.0, 0.0, 1.0)
 
 	//gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
@@ -127,180 +309,53 @@ func SetMode(title string, width, height int) (*Context, error) {
 	return &c, nil
 }
 
-// Fill TODO doc
-func (c *Context) Fill(r, g, b float32) {
-	gl.ClearColor(r, g, b, 1.0)
+// Flusher queues drawing, which must be finished before a frame is shown.
+type Flusher interface {
+	Flush()
 }
 
-// Flip TODO doc
-func (c *Context) Flip() {
-	c.Window.SwapBuffers()
+// AddFlusher so it is flushed at the start of Flip and before ReadPixels.
+func (c *Context) AddFlusher(f Flusher) {
+	c.flushers = append(c.flushers, f)
 }
 
-func newProgram(vertexShaderSource, fragmentShaderSource string) (uint32, error) {
-	vertShader, err := compileShader(vertexShaderSource, gl.VERTEX_SHADER)
-	if err != nil {
-		return 0, fmt.Errorf("can not create vert shader: %s", err)
-	}
-
-	fragShader, err := compileShader(fragmentShaderSource, gl.FRAGMENT_SHADER)
-	if err != nil {
-		return 0, fmt.Errorf("can not create frag shader: %s", err)
-	}
-
-	program := gl.CreateProgram()
-	gl.AttachShader(program, vertShader)
-	gl.AttachShader(program, fragShader)
-	gl.LinkProgram(program)
-
-	var status int32
-	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
-
-		return 0, fmt.Errorf("failed to link program: %v", log)
+// RemoveFlusher added by AddFlusher.
+func (c *Context) RemoveFlusher(f Flusher) {
+	for i := range c.flushers {
+		if c.flushers[i] == f {
+			c.flushers = append(c.flushers[:i], c.flushers[i+1:]...)
+			return
+		}
 	}
-
-	gl.DeleteShader(vertShader)
-	gl.DeleteShader(fragShader)
-
-	return program, nil
 }
 
-func compileShader(source string, shaderType uint32) (uint32, error) {
-	shader := gl.CreateShader(shaderType)
-
-	csource := gl.Str(source)
-	gl.ShaderSource(shader, 1, &csource, nil)
-	gl.CompileShader(shader)
-
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := strings.Repeat("\x00", int(logLength+1))
-		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
-
-		return 0, fmt.Errorf("failed to compile %v: %v", source, log)
+func (c *Context) flush() {
+	for _, f := range c.flushers {
+		f.Flush()
 	}
-
-	return shader, nil
 }
 
-var vertexShader = `
-#version 120
-
-uniform mat4 ProjMatrix;
-uniform mat4 ViewMatrix;
-uniform mat4 ModelMatrix;
-uniform mat3 TexMatrix;
-uniform vec3 LightPos;
-
-attribute vec3 MCVertex;
-attribute vec3 MCNormal;
-attribute vec3 MCTangent;
-attribute vec2 TexCoord0;
-
-varying vec2 TexCoord;
-varying vec3 Pos;
-varying vec3 LightDir;
-varying vec3 EyeDir;
-
-
-mat3 inv(mat3 m) {
-	// TDOO implement inverse func
-	return m;
+// Fill TODO doc
+func (c *Context) Fill(r, g, b float32) {
+	gl.ClearColor(r, g, b, 1.0)
 }
 
-void main() {
-  mat4 mvMatrix = ViewMatrix * ModelMatrix;
-  vec4 ccVertex = mvMatrix * vec4(MCVertex, 1.0);
-  gl_Position = ProjMatrix * ccVertex;
-  Pos = vec4(ModelMatrix * vec4(MCVertex, 1.0)).xyz;
-
-  TexCoord = vec3(TexMatrix * vec3(TexCoord0, 1.0)).st;
-
-  mat3 normalMatrix = mat3x3(mvMatrix);
-  normalMatrix = inv(normalMatrix);
-  normalMatrix = transpose(normalMatrix);
-
-  mat3 mv3Matrix = mat3(mvMatrix);
-  vec3 n = normalize(MCNormal); // TODO: fix normalize(mv3Matrix * MCNormal);
-  vec3 t = normalize(mv3Matrix * MCTangent);
-  vec3 b = normalize(mv3Matrix * cross(n, t));
-
-  LightDir = vec3(ViewMatrix * vec4(LightPos, 0.0)) - vec3(ccVertex);
-  vec3 v;
-  v.x = dot(LightDir, t);
-  v.y = dot(LightDir, b);
-  v.z = dot(LightDir, n);
-  LightDir = v;
-
-  EyeDir = vec3(-ccVertex);
-  v.x = dot(EyeDir, t);
-  v.y = dot(EyeDir, b);
-  v.z = dot(EyeDir, n);
-  EyeDir = v;
-}
-` + "\x00"
-
-var fragmentShader = `
-#version 120
-
-uniform int AddColor;
-uniform vec4 AColor;
-uniform int SubColor;
-uniform vec4 SColor;
-uniform sampler2D ColorMap;
-uniform sampler2D NormalMap;
-uniform vec4 AmbientColor;
-uniform vec3 LightPos;
-uniform vec4 LightColor;
-uniform float LightPower;
-
-varying vec3 Pos;
-varying vec3 LightDir;
-varying vec3 EyeDir;
-varying vec2 TexCoord;
-
-// out vec4 FragColor;
-
-void main() {
-  float alpha = texture2D(ColorMap, TexCoord.st).a;
-  vec3 diffuse = texture2D(ColorMap, TexCoord.st).rgb;
-  if (AddColor == 1) {
-    diffuse = clamp(diffuse + AColor.rgb, 0.0, 1.0);
-  }
-  if (SubColor == 1) {
-    diffuse = clamp(diffuse - SColor.rgb, 0.0, 1.0);
-  }
-  vec3 ambient = AmbientColor.rgb * diffuse;
-  vec3 specular = diffuse/8;
-
-  vec3 normal = texture2D(NormalMap, TexCoord.st).rgb * 2 - 1;
-  float distance = length(LightPos - Pos);
-
-  vec3 n = normalize(normal);
-  vec3 l = normalize(LightDir);
-
-  float cosTheta = clamp(dot(n, l), 0.0, 1.0);
-
-  vec3 e = normalize(EyeDir);
-  vec3 r = reflect(-l, n);
-
-  float cosAlpha = clamp(dot(e, r), 0.0, 1.0);
-
-  gl_FragColor = vec4(
-    ambient +
-    diffuse * LightColor.rgb * LightPower * cosTheta /
-      (distance * distance) +
-    specular * LightColor.rgb * LightPower * pow(cosAlpha, 5) /
-      (distance * distance), alpha);
+// Flip TODO doc
+func (c *Context) Flip() {
+	c.flush()
+	c.checkShaders()
+	if len(c.passes) > 0 {
+		c.postProcess()
+	}
+	c.capture()
+	if c.Offscreen {
+		// Nothing to swap, make sure the frame is finished so it can be read back.
+		gl.Finish()
+	} else {
+		c.Window.SwapBuffers()
+	}
+	if len(c.passes) > 0 {
+		// Draw the next frame to the scene again.
+		c.BindScreen()
+	}
 }
-` + "\x00"
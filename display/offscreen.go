@@ -0,0 +1,98 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/hurricanerix/shade/gpu"
+)
+
+// createFramebuffer with color and depth attachments and bind it, so all
+// following draw calls render into it instead of the window.
+func (c *Context) createFramebuffer(width, height int) error {
+	gl.GenFramebuffers(1, &c.framebuffer)
+	gpu.Created(gpu.Framebuffer, c.framebuffer)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, c.framebuffer)
+
+	gl.GenRenderbuffers(1, &c.colorbuffer)
+	gpu.Created(gpu.Renderbuffer, c.colorbuffer)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, c.colorbuffer)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.RGBA8, int32(width), int32(height))
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, c.colorbuffer)
+
+	gl.GenRenderbuffers(1, &c.depthbuffer)
+	gpu.Created(gpu.Renderbuffer, c.depthbuffer)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, c.depthbuffer)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(width), int32(height))
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, c.depthbuffer)
+
+	if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+		return fmt.Errorf("offscreen framebuffer is incomplete: 0x%x", status)
+	}
+
+	c.setViewport(0, 0, width, height)
+	return nil
+}
+
+// deleteFramebuffer created by createFramebuffer, if any.
+func (c *Context) deleteFramebuffer() {
+	if c.framebuffer == 0 {
+		return
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	gl.DeleteFramebuffers(1, &c.framebuffer)
+	gpu.Deleted(gpu.Framebuffer, c.framebuffer)
+	gl.DeleteRenderbuffers(1, &c.colorbuffer)
+	gpu.Deleted(gpu.Renderbuffer, c.colorbuffer)
+	gl.DeleteRenderbuffers(1, &c.depthbuffer)
+	gpu.Deleted(gpu.Renderbuffer, c.depthbuffer)
+	c.framebuffer, c.colorbuffer, c.depthbuffer = 0, 0, 0
+}
+
+// ReadPixels returns the contents of the current frame's viewport in framebuffer
+// pixels.  When drawing to a window it must be called before Flip, since the back
+// buffer is undefined after a swap.  With post processing enabled this is the frame
+// before any passes are applied.
+func (c *Context) ReadPixels() *image.RGBA {
+	c.flush()
+	x, y, w, h := c.Viewport()
+	if c.scene != nil {
+		x, y = 0, 0
+	}
+	return readPixels(x, y, w, h)
+}
+
+// readPixels in the rectangle x, y, width, height of the bound framebuffer.
+func readPixels(x, y, width, height int32) *image.RGBA {
+	w, h := int(width), int(height)
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(x, y, width, height, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+
+	// OpenGL's origin is the bottom left, image's is the top left.
+	row := make([]uint8, img.Stride)
+	for y := 0; y < h/2; y++ {
+		top := img.Pix[y*img.Stride : (y+1)*img.Stride]
+		bottom := img.Pix[(h-1-y)*img.Stride : (h-y)*img.Stride]
+		copy(row, top)
+		copy(top, bottom)
+		copy(bottom, row)
+	}
+	return img
+}
@@ -0,0 +1,487 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/hurricanerix/shade/gpu"
+	"github.com/hurricanerix/shade/shader"
+)
+
+// Pass is a full screen post processing pass, see SetPostProcess.
+type Pass struct {
+	Program  *shader.Program
+	uniforms map[string][]float32
+	textures map[string]uint32
+	// owned textures are created by the pass and deleted with it
+	owned []uint32
+}
+
+// Set a float, vec2, vec3 or vec4 uniform of the pass, depending on how many
+// values are given.
+func (p *Pass) Set(name string, values ...float32) {
+	p.uniforms[name] = values
+}
+
+// SetTexture binds texture to the pass's sampler2D uniform name.
+func (p *Pass) SetTexture(name string, texture uint32) {
+	p.textures[name] = texture
+}
+
+// Delete the pass's program and any textures it created.  It must not be used
+// afterwards.
+func (p *Pass) Delete() {
+	p.Program.Delete()
+	for i := range p.owned {
+		gl.DeleteTextures(1, &p.owned[i])
+		gpu.Deleted(gpu.Texture, p.owned[i])
+	}
+	p.owned = nil
+}
+
+// draw the pass over the bound framebuffer, reading from scene and source.
+func (p *Pass) draw(scene, source uint32, width, height int, seconds float32) {
+	p.Program.Use()
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, scene)
+	gl.Uniform1i(p.Program.Uniform("Scene"), 0)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, source)
+	gl.Uniform1i(p.Program.Uniform("Source"), 1)
+
+	unit := int32(2)
+	for name, texture := range p.textures {
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(unit))
+		gl.BindTexture(gl.TEXTURE_2D, texture)
+		gl.Uniform1i(p.Program.Uniform(name), unit)
+		unit++
+	}
+
+	gl.Uniform2f(p.Program.Uniform("Resolution"), float32(width), float32(height))
+	gl.Uniform1f(p.Program.Uniform("Time"), seconds)
+	for name, v := range p.uniforms {
+		loc := p.Program.Uniform(name)
+		switch len(v) {
+		case 1:
+			gl.Uniform1f(loc, v[0])
+		case 2:
+			gl.Uniform2fv(loc, 1, &v[0])
+		case 3:
+			gl.Uniform3fv(loc, 1, &v[0])
+		case 4:
+			gl.Uniform4fv(loc, 1, &v[0])
+		}
+	}
+
+	gl.DrawArrays(gl.TRIANGLES, 0, 6)
+}
+
+// NewPass compiles a post processing pass from the body of a fragment shader.  A
+// header matching the Context's GLSL version is prepended, which declares:
+//
+//	uniform sampler2D Scene;   // the frame as drawn, before any pass
+//	uniform sampler2D Source;  // output of the previous pass (Scene for the first)
+//	uniform vec2 Resolution;   // size of Source in pixels
+//	uniform float Time;        // seconds since post processing was enabled
+//	IN vec2 TexCoord;          // position on the screen from (0, 0) to (1, 1)
+//
+// Bodies must use the IN, TEXTURE and FragColor macros rather than
+// varying/in, texture2D/texture and gl_FragColor so they work with every version.
+func (c *Context) NewPass(fragmentBody string) (*Pass, error) {
+	program, err := shader.New(c.passVertexShader(), c.passFragmentHeader()+fragmentBody)
+	if err != nil {
+		return nil, fmt.Errorf("could not create post processing pass: %v", err)
+	}
+	return &Pass{
+		Program:  program,
+		uniforms: map[string][]float32{},
+		textures: map[string]uint32{},
+	}, nil
+}
+
+// SetPostProcess applies passes, in order, to each frame in Flip.  Everything drawn
+// to the screen is drawn to a texture which is the input for the first pass, and
+// the last pass draws to the screen.  Calling it with no passes turns post
+// processing off.
+func (c *Context) SetPostProcess(passes ...*Pass) error {
+	c.postStart = time.Now()
+	if len(passes) == 0 {
+		c.passes = nil
+		c.deleteTargets()
+		c.BindScreen()
+		return nil
+	}
+
+	if c.postVAO == 0 {
+		gl.GenVertexArrays(1, &c.postVAO)
+		gpu.Created(gpu.VertexArray, c.postVAO)
+		gl.BindVertexArray(c.postVAO)
+		gl.GenBuffers(1, &c.postVBO)
+		gpu.Created(gpu.Buffer, c.postVBO)
+		gl.BindBuffer(gl.ARRAY_BUFFER, c.postVBO)
+		gl.BufferData(gl.ARRAY_BUFFER, len(quadVertices)*4, gl.Ptr(quadVertices), gl.STATIC_DRAW)
+		gl.EnableVertexAttribArray(shader.MCVertexLoc)
+		gl.VertexAttribPointer(shader.MCVertexLoc, 2, gl.FLOAT, false, 4*4, gl.PtrOffset(0))
+		gl.EnableVertexAttribArray(shader.TexCoord0Loc)
+		gl.VertexAttribPointer(shader.TexCoord0Loc, 2, gl.FLOAT, false, 4*4, gl.PtrOffset(2*4))
+	}
+	if err := c.createTargets(); err != nil {
+		// Without targets there is nothing for the passes to read, so draw straight
+		// to the screen instead.
+		c.passes = nil
+		c.BindScreen()
+		return fmt.Errorf("could not enable post processing: %v", err)
+	}
+	c.passes = passes
+	return nil
+}
+
+// createTargets the size of the viewport for post processing and bind the scene.
+func (c *Context) createTargets() error {
+	c.deleteTargets()
+	w, h := int(c.viewport[2]), int(c.viewport[3])
+	var err error
+	if c.scene, err = NewRenderTarget(w, h); err != nil {
+		return err
+	}
+	for i := range c.pingpong {
+		if c.pingpong[i], err = NewRenderTarget(w, h); err != nil {
+			c.deleteTargets()
+			return err
+		}
+	}
+	c.BindScreen()
+	return nil
+}
+
+func (c *Context) deleteTargets() {
+	if c.scene != nil {
+		c.scene.Delete()
+		c.scene = nil
+	}
+	for i := range c.pingpong {
+		if c.pingpong[i] != nil {
+			c.pingpong[i].Delete()
+			c.pingpong[i] = nil
+		}
+	}
+}
+
+// deleteQuad drawn by the passes, if any.
+func (c *Context) deleteQuad() {
+	if c.postVAO == 0 {
+		return
+	}
+	gl.DeleteVertexArrays(1, &c.postVAO)
+	gpu.Deleted(gpu.VertexArray, c.postVAO)
+	gl.DeleteBuffers(1, &c.postVBO)
+	gpu.Deleted(gpu.Buffer, c.postVBO)
+	c.postVAO, c.postVBO = 0, 0
+}
+
+// postProcess the scene to the screen, ping-ponging between two targets for the
+// passes in between.
+func (c *Context) postProcess() {
+	if c.scene == nil {
+		return
+	}
+	seconds := float32(time.Since(c.postStart).Seconds())
+	gl.Disable(gl.BLEND)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.BindVertexArray(c.postVAO)
+
+	source := c.scene
+	for i, p := range c.passes {
+		var target *RenderTarget
+		if i == len(c.passes)-1 {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, c.framebuffer)
+			gl.Viewport(c.viewport[0], c.viewport[1], c.viewport[2], c.viewport[3])
+			gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+		} else {
+			target = c.pingpong[i%2]
+			target.Bind()
+		}
+		p.draw(c.scene.Texture(), source.Texture(), source.Width, source.Height, seconds)
+		source = target
+	}
+
+	gl.Enable(gl.BLEND)
+	gl.Enable(gl.DEPTH_TEST)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.UseProgram(c.Program)
+}
+
+func (c *Context) passVertexShader() string {
+	if c.Version.GLSL >= 330 {
+		return fmt.Sprintf("#version %d core\n", c.Version.GLSL) + `
+in vec3 MCVertex;
+in vec2 TexCoord0;
+out vec2 TexCoord;
+
+void main() {
+  TexCoord = TexCoord0;
+  gl_Position = vec4(MCVertex.xy, 0.0, 1.0);
+}
+`
+	}
+	return `
+#version 120
+
+attribute vec3 MCVertex;
+attribute vec2 TexCoord0;
+varying vec2 TexCoord;
+
+void main() {
+  TexCoord = TexCoord0;
+  gl_Position = vec4(MCVertex.xy, 0.0, 1.0);
+}
+`
+}
+
+func (c *Context) passFragmentHeader() string {
+	header := `
+#version 120
+#define IN varying
+#define TEXTURE texture2D
+#define FragColor gl_FragColor
+`
+	if c.Version.GLSL >= 330 {
+		header = fmt.Sprintf("#version %d core\n", c.Version.GLSL) + `
+#define IN in
+#define TEXTURE texture
+layout(location = 0) out vec4 FragColor;
+`
+	}
+	return header + `
+uniform sampler2D Scene;
+uniform sampler2D Source;
+uniform vec2 Resolution;
+uniform float Time;
+IN vec2 TexCoord;
+`
+}
+
+// NewGrayscalePass removes all color from the frame.
+func (c *Context) NewGrayscalePass() (*Pass, error) {
+	return c.NewPass(`
+void main() {
+  vec4 color = TEXTURE(Source, TexCoord);
+  float l = dot(color.rgb, vec3(0.299, 0.587, 0.114));
+  FragColor = vec4(vec3(l), color.a);
+}
+`)
+}
+
+// NewVignettePass darkens the frame towards its corners.  Pixels further than radius
+// from the center (0.5 is the middle of an edge) are darkened by up to strength.
+func (c *Context) NewVignettePass(strength, radius float32) (*Pass, error) {
+	p, err := c.NewPass(`
+uniform float Strength;
+uniform float Radius;
+
+void main() {
+  vec4 color = TEXTURE(Source, TexCoord);
+  float d = distance(TexCoord, vec2(0.5, 0.5));
+  float v = 1.0 - smoothstep(Radius - 0.35, Radius, d);
+  FragColor = vec4(color.rgb * mix(1.0, v, Strength), color.a);
+}
+`)
+	if err != nil {
+		return nil, err
+	}
+	p.Set("Strength", strength)
+	p.Set("Radius", radius)
+	return p, nil
+}
+
+// NewScanlinesPass gives the frame the look of a CRT, with slightly curved edges and
+// scanlines darkened by intensity (0 to 1).
+func (c *Context) NewScanlinesPass(intensity float32) (*Pass, error) {
+	p, err := c.NewPass(`
+uniform float Intensity;
+
+void main() {
+  vec2 uv = TexCoord * 2.0 - 1.0;
+  uv *= 1.0 + dot(uv, uv) * 0.02;
+  uv = uv * 0.5 + 0.5;
+  if (uv.x < 0.0 || uv.x > 1.0 || uv.y < 0.0 || uv.y > 1.0) {
+    FragColor = vec4(0.0, 0.0, 0.0, 1.0);
+    return;
+  }
+  vec4 color = TEXTURE(Source, uv);
+  float line = 0.5 + 0.5 * sin(uv.y * Resolution.y * 3.14159265);
+  FragColor = vec4(color.rgb * (1.0 - Intensity * (1.0 - line)), color.a);
+}
+`)
+	if err != nil {
+		return nil, err
+	}
+	p.Set("Intensity", intensity)
+	return p, nil
+}
+
+// NewBlurPasses returns a horizontal and a vertical gaussian blur pass, spreading
+// each pixel over about 4 * radius pixels.
+func (c *Context) NewBlurPasses(radius float32) ([]*Pass, error) {
+	h, err := c.newBlurPass(radius, 0)
+	if err != nil {
+		return nil, err
+	}
+	v, err := c.newBlurPass(0, radius)
+	if err != nil {
+		h.Delete()
+		return nil, err
+	}
+	return []*Pass{h, v}, nil
+}
+
+func (c *Context) newBlurPass(dx, dy float32) (*Pass, error) {
+	p, err := c.NewPass(`
+uniform vec2 Direction;
+
+void main() {
+  vec2 s = Direction / Resolution;
+  vec4 sum = TEXTURE(Source, TexCoord) * 0.227027;
+  sum += TEXTURE(Source, TexCoord + s * 1.0) * 0.1945946;
+  sum += TEXTURE(Source, TexCoord - s * 1.0) * 0.1945946;
+  sum += TEXTURE(Source, TexCoord + s * 2.0) * 0.1216216;
+  sum += TEXTURE(Source, TexCoord - s * 2.0) * 0.1216216;
+  sum += TEXTURE(Source, TexCoord + s * 3.0) * 0.054054;
+  sum += TEXTURE(Source, TexCoord - s * 3.0) * 0.054054;
+  sum += TEXTURE(Source, TexCoord + s * 4.0) * 0.016216;
+  sum += TEXTURE(Source, TexCoord - s * 4.0) * 0.016216;
+  FragColor = sum;
+}
+`)
+	if err != nil {
+		return nil, err
+	}
+	p.Set("Direction", dx, dy)
+	return p, nil
+}
+
+// NewBloomPasses makes parts of the frame brighter than threshold (0 to 1) glow.
+// The glow is added to the frame as drawn, so the bloom passes should come first
+// when combined with other passes.
+func (c *Context) NewBloomPasses(threshold, intensity float32) ([]*Pass, error) {
+	bright, err := c.NewPass(`
+uniform float Threshold;
+
+void main() {
+  vec4 color = TEXTURE(Source, TexCoord);
+  float l = dot(color.rgb, vec3(0.2126, 0.7152, 0.0722));
+  FragColor = vec4(color.rgb * max(l - Threshold, 0.0) / max(l, 0.0001), 1.0);
+}
+`)
+	if err != nil {
+		return nil, err
+	}
+	bright.Set("Threshold", threshold)
+
+	blur, err := c.NewBlurPasses(2)
+	if err != nil {
+		bright.Delete()
+		return nil, err
+	}
+
+	combine, err := c.NewPass(`
+uniform float Intensity;
+
+void main() {
+  vec4 scene = TEXTURE(Scene, TexCoord);
+  vec3 bloom = TEXTURE(Source, TexCoord).rgb;
+  FragColor = vec4(scene.rgb + bloom * Intensity, scene.a);
+}
+`)
+	if err != nil {
+		bright.Delete()
+		for _, p := range blur {
+			p.Delete()
+		}
+		return nil, err
+	}
+	combine.Set("Intensity", intensity)
+
+	return append(append([]*Pass{bright}, blur...), combine), nil
+}
+
+// NewColorGradePass maps every color through a color lookup table, blended with the
+// original color by mix (0 to 1).  lut is a strip of size square slices laid out
+// left to right, one per blue value, each with red increasing to the right and
+// green increasing downwards, so it is size*size pixels wide and size pixels high.
+func (c *Context) NewColorGradePass(lut image.Image, mix float32) (*Pass, error) {
+	b := lut.Bounds()
+	size := b.Dy()
+	if size < 2 || b.Dx() != size*size {
+		return nil, fmt.Errorf("color lookup table must be size*size x size pixels, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	p, err := c.NewPass(`
+uniform sampler2D LUT;
+uniform float LUTSize;
+uniform float Mix;
+
+void main() {
+  vec4 color = TEXTURE(Source, TexCoord);
+  vec3 c = clamp(color.rgb, 0.0, 1.0);
+  float n = LUTSize;
+  float b = c.b * (n - 1.0);
+  float b0 = floor(b);
+  float b1 = min(b0 + 1.0, n - 1.0);
+  vec2 rg = (c.rg * (n - 1.0) + 0.5) / vec2(n * n, n);
+  vec3 c0 = TEXTURE(LUT, rg + vec2(b0 / n, 0.0)).rgb;
+  vec3 c1 = TEXTURE(LUT, rg + vec2(b1 / n, 0.0)).rgb;
+  FragColor = vec4(mix(color.rgb, mix(c0, c1, b - b0), Mix), color.a);
+}
+`)
+	if err != nil {
+		return nil, err
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), lut, b.Min, draw.Src)
+
+	var texture uint32
+	gl.GenTextures(1, &texture)
+	gpu.Created(gpu.Texture, texture)
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(b.Dx()), int32(b.Dy()), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
+
+	p.SetTexture("LUT", texture)
+	p.owned = append(p.owned, texture)
+	p.Set("LUTSize", float32(size))
+	p.Set("Mix", mix)
+	return p, nil
+}
+
+// Position(X, Y), TextureCo(S, T) of two triangles covering the screen.
+var quadVertices = []float32{
+	-1.0, -1.0, 0.0, 0.0,
+	1.0, -1.0, 1.0, 0.0,
+	1.0, 1.0, 1.0, 1.0,
+	-1.0, -1.0, 0.0, 0.0,
+	1.0, 1.0, 1.0, 1.0,
+	-1.0, 1.0, 0.0, 1.0,
+}
@@ -0,0 +1,93 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display_test
+
+import (
+	"testing"
+
+	"github.com/hurricanerix/shade/camera"
+	"github.com/hurricanerix/shade/display"
+	"github.com/hurricanerix/shade/shadetest"
+)
+
+func TestMain(m *testing.M) {
+	shadetest.Main(m)
+}
+
+// failedTargetsScene turns post processing on while the viewport is too small for
+// its targets, either before enabling it or by resizing afterwards, then flips a
+// frame with the failed state.
+type failedTargetsScene struct {
+	resize  bool
+	screen  *display.Context
+	pass    *display.Pass
+	err     error
+	enabled bool
+}
+
+func (s *failedTargetsScene) Setup(screen *display.Context, cam *camera.Context) error {
+	var err error
+	s.screen = screen
+	if s.pass, err = screen.NewGrayscalePass(); err != nil {
+		return err
+	}
+	_, _, w, h := screen.Viewport()
+	if s.resize {
+		if err := screen.SetPostProcess(s.pass); err != nil {
+			return err
+		}
+		screen.SetViewport(0, 0, 0, 0)
+	} else {
+		screen.SetViewport(0, 0, 0, 0)
+		s.err = screen.SetPostProcess(s.pass)
+	}
+	s.enabled = screen.PostProcessing()
+	// Flip with the failed state, then restore the viewport for ReadPixels and the
+	// tests sharing the screen.
+	screen.Flip()
+	screen.SetViewport(0, 0, int(w), int(h))
+	return nil
+}
+
+func (s *failedTargetsScene) Frame(n int, dt float32) {}
+
+func (s *failedTargetsScene) release() {
+	s.screen.SetPostProcess()
+	s.pass.Delete()
+}
+
+func TestSetPostProcessFailure(t *testing.T) {
+	tests := []struct {
+		resize bool
+		err    bool
+	}{
+		{false, true},
+		{true, false},
+	}
+
+	for _, tt := range tests {
+		s := failedTargetsScene{resize: tt.resize}
+		if _, err := shadetest.Render(&s, shadetest.Options{}); err != nil {
+			t.Skip(err)
+		}
+		if (s.err != nil) != tt.err {
+			t.Error("Expected error", tt.err, "but found", s.err)
+		}
+		if s.enabled {
+			t.Error("Expected post processing to be off for resize", tt.resize, "but found it on")
+		}
+		shadetest.Do(s.release)
+	}
+}
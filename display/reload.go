@@ -0,0 +1,119 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// How often shader files are checked for changes.
+const watchInterval = 250 * time.Millisecond
+
+// shaderWatch tracks the shader files used by WatchShaders.
+type shaderWatch struct {
+	vertexPath   string
+	fragmentPath string
+	modified     time.Time
+	checked      time.Time
+}
+
+// WatchShaders loads the display's program from the GLSL files at vertexPath and
+// fragmentPath, then reloads it whenever either file changes on disk.  This is meant
+// for development, so shaders can be iterated on without restarting the game.
+//
+// Changes are checked for in Flip.  If a changed shader fails to compile, the
+// previous program keeps running, the compile log is printed, and ShaderErr is set
+// so the game can show it on screen.  ShaderErr is cleared by the next successful
+// reload.
+func (c *Context) WatchShaders(vertexPath, fragmentPath string) error {
+	c.watch = &shaderWatch{
+		vertexPath:   vertexPath,
+		fragmentPath: fragmentPath,
+	}
+	c.reloadShaders()
+	return c.ShaderErr
+}
+
+// checkShaders reloads the watched shaders if they changed since the last check.
+func (c *Context) checkShaders() {
+	if c.watch == nil || time.Since(c.watch.checked) < watchInterval {
+		return
+	}
+	c.watch.checked = time.Now()
+
+	modified, err := lastModified(c.watch.vertexPath, c.watch.fragmentPath)
+	if err != nil {
+		c.setShaderErr(err)
+		return
+	}
+	if modified.After(c.watch.modified) {
+		c.reloadShaders()
+	}
+}
+
+func (c *Context) reloadShaders() {
+	modified, err := lastModified(c.watch.vertexPath, c.watch.fragmentPath)
+	if err != nil {
+		c.setShaderErr(err)
+		return
+	}
+	c.watch.modified = modified
+	c.watch.checked = time.Now()
+
+	vertexSource, err := ioutil.ReadFile(c.watch.vertexPath)
+	if err != nil {
+		c.setShaderErr(err)
+		return
+	}
+	fragmentSource, err := ioutil.ReadFile(c.watch.fragmentPath)
+	if err != nil {
+		c.setShaderErr(err)
+		return
+	}
+
+	if err := c.Shader.Reload(string(vertexSource), string(fragmentSource)); err != nil {
+		c.setShaderErr(fmt.Errorf("could not reload shaders: %v", err))
+		return
+	}
+	c.Program = c.Shader.ID
+	c.ShaderErr = nil
+	fmt.Println("Reloaded shaders", c.watch.vertexPath, c.watch.fragmentPath)
+}
+
+func (c *Context) setShaderErr(err error) {
+	if c.ShaderErr == nil || c.ShaderErr.Error() != err.Error() {
+		// Only report each error once, not every check.
+		fmt.Println("Warning:", err)
+	}
+	c.ShaderErr = err
+}
+
+// lastModified returns the newest modification time of the files at paths.
+func lastModified(paths ...string) (time.Time, error) {
+	var t time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return t, fmt.Errorf("could not watch shader: %v", err)
+		}
+		if info.ModTime().After(t) {
+			t = info.ModTime()
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,339 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+// GLSL 1.20 shaders used with OpenGL 2.1 contexts.
+var vertexShader120 = `
+#version 120
+
+uniform mat4 ProjMatrix;
+uniform mat4 ViewMatrix;
+uniform mat4 ModelMatrix;
+uniform mat3 TexMatrix;
+uniform vec3 LightPos;
+
+attribute vec3 MCVertex;
+attribute vec3 MCNormal;
+attribute vec4 MCTangent;
+attribute vec2 TexCoord0;
+
+varying vec2 TexCoord;
+varying vec3 Pos;
+varying vec3 LightDir;
+varying vec3 EyeDir;
+
+
+// inv of m, as GLSL 1.20 has no inverse().  The rows of the inverse are the cross
+// products of m's columns, divided by its determinant.
+mat3 inv(mat3 m) {
+  vec3 r0 = cross(m[1], m[2]);
+  vec3 r1 = cross(m[2], m[0]);
+  vec3 r2 = cross(m[0], m[1]);
+  return transpose(mat3(r0, r1, r2)) / dot(m[0], r0);
+}
+
+void main() {
+  mat4 mvMatrix = ViewMatrix * ModelMatrix;
+  vec4 ccVertex = mvMatrix * vec4(MCVertex, 1.0);
+  gl_Position = ProjMatrix * ccVertex;
+  Pos = vec4(ModelMatrix * vec4(MCVertex, 1.0)).xyz;
+
+  TexCoord = vec3(TexMatrix * vec3(TexCoord0, 1.0)).st;
+
+  mat3 mv3Matrix = mat3(mvMatrix);
+  mat3 normalMatrix = transpose(inv(mv3Matrix));
+  vec3 n = normalize(normalMatrix * MCNormal);
+  vec3 t = normalize(mv3Matrix * MCTangent.xyz);
+  // A flipped sprite mirrors its tangent space, see the sprite package's vertices.
+  float det = mv3Matrix[0][0] * mv3Matrix[1][1] - mv3Matrix[1][0] * mv3Matrix[0][1];
+  vec3 b = MCTangent.w * sign(det) * cross(n, t);
+
+  LightDir = vec3(ViewMatrix * vec4(LightPos, 0.0)) - vec3(ccVertex);
+  vec3 v;
+  v.x = dot(LightDir, t);
+  v.y = dot(LightDir, b);
+  v.z = dot(LightDir, n);
+  LightDir = v;
+
+  EyeDir = vec3(-ccVertex);
+  v.x = dot(EyeDir, t);
+  v.y = dot(EyeDir, b);
+  v.z = dot(EyeDir, n);
+  EyeDir = v;
+}
+` + "\x00"
+
+var fragmentShader120 = `
+#version 120
+
+uniform int AddColor;
+uniform vec4 AColor;
+uniform int SubColor;
+uniform vec4 SColor;
+uniform vec4 MColor;
+// Blend is the sprite.BlendMode the fragment is blended with, 2 is Multiply and 3
+// Screen.
+uniform int Blend;
+uniform sampler2D ColorMap;
+uniform sampler2D NormalMap;
+uniform vec4 AmbientColor;
+uniform vec3 LightPos;
+uniform vec4 LightColor;
+uniform float LightPower;
+uniform int UseShadowMap;
+uniform sampler2D ShadowMap;
+uniform vec2 ShadowTexel;
+uniform float ShadowHeight;
+uniform float ShadowStrength;
+// ShadowFrame is the frame being drawn, as its min and max texture coordinates.
+uniform vec4 ShadowFrame;
+
+varying vec3 Pos;
+varying vec3 LightDir;
+varying vec3 EyeDir;
+varying vec2 TexCoord;
+
+// out vec4 FragColor;
+
+// Steps taken towards the light looking for parts of the shadow map which block it.
+const int shadowSteps = 16;
+
+// shadow returns how much of the light in direction l, in tangent space, reaches
+// the fragment past the heights of the shadow map.
+float shadow(vec3 l) {
+  if (UseShadowMap != 1 || l.z <= 0.0) {
+    return 1.0;
+  }
+  float height = texture2D(ShadowMap, TexCoord.st).r * ShadowHeight;
+  // Texels moved for each texel the ray rises.  T increases down the texture,
+  // tangent space y is up.
+  vec2 dir = vec2(l.x, -l.y) / max(l.z, 0.1) * ShadowTexel;
+  float rise = (ShadowHeight - height) / float(shadowSteps);
+  for (int i = 1; i <= shadowSteps; i++) {
+    float ray = rise * float(i);
+    vec2 st = TexCoord.st + dir * ray;
+    // Past the edge of the frame is another frame, or the other side of the
+    // texture, which can't block the light.
+    if (any(lessThan(st, ShadowFrame.xy)) || any(greaterThan(st, ShadowFrame.zw))) {
+      break;
+    }
+    if (texture2D(ShadowMap, st).r * ShadowHeight > height + ray) {
+      return 1.0 - ShadowStrength;
+    }
+  }
+  return 1.0;
+}
+
+void main() {
+  float alpha = texture2D(ColorMap, TexCoord.st).a;
+  if (alpha == 0.0) {
+    discard;
+  }
+  vec3 diffuse = texture2D(ColorMap, TexCoord.st).rgb;
+  if (AddColor == 1) {
+    diffuse = clamp(diffuse + AColor.rgb, 0.0, 1.0);
+  }
+  if (SubColor == 1) {
+    diffuse = clamp(diffuse - SColor.rgb, 0.0, 1.0);
+  }
+  diffuse *= MColor.rgb;
+  alpha *= MColor.a;
+  vec3 ambient = AmbientColor.rgb * diffuse;
+  vec3 specular = diffuse/8;
+
+  vec3 normal = texture2D(NormalMap, TexCoord.st).rgb * 2 - 1;
+  float distance = length(LightPos - Pos);
+
+  vec3 n = normalize(normal);
+  vec3 l = normalize(LightDir);
+
+  float cosTheta = clamp(dot(n, l), 0.0, 1.0);
+
+  vec3 e = normalize(EyeDir);
+  vec3 r = reflect(-l, n);
+
+  float cosAlpha = clamp(dot(e, r), 0.0, 1.0);
+  float lit = shadow(l);
+
+  vec3 color = ambient +
+    lit * diffuse * LightColor.rgb * LightPower * cosTheta /
+      (distance * distance) +
+    lit * specular * LightColor.rgb * LightPower * pow(cosAlpha, 5) /
+      (distance * distance);
+  // Multiply and Screen blend by color only, so fade the color by alpha towards
+  // the one which leaves the destination unchanged, white and black.
+  if (Blend == 2) {
+    color = mix(vec3(1.0), color, alpha);
+  } else if (Blend == 3) {
+    color *= alpha;
+  }
+  gl_FragColor = vec4(color, alpha);
+}
+` + "\x00"
+
+// Core profile shaders, see versions for the #version line prepended to them.
+var coreVertexShader = `
+uniform mat4 ProjMatrix;
+uniform mat4 ViewMatrix;
+uniform mat4 ModelMatrix;
+uniform mat3 TexMatrix;
+uniform vec3 LightPos;
+
+in vec3 MCVertex;
+in vec3 MCNormal;
+in vec4 MCTangent;
+in vec2 TexCoord0;
+
+out vec2 TexCoord;
+out vec3 Pos;
+out vec3 LightDir;
+out vec3 EyeDir;
+
+void main() {
+  mat4 mvMatrix = ViewMatrix * ModelMatrix;
+  vec4 ccVertex = mvMatrix * vec4(MCVertex, 1.0);
+  gl_Position = ProjMatrix * ccVertex;
+  Pos = vec4(ModelMatrix * vec4(MCVertex, 1.0)).xyz;
+
+  TexCoord = vec3(TexMatrix * vec3(TexCoord0, 1.0)).st;
+
+  mat3 mv3Matrix = mat3(mvMatrix);
+  mat3 normalMatrix = transpose(inverse(mv3Matrix));
+  vec3 n = normalize(normalMatrix * MCNormal);
+  vec3 t = normalize(mv3Matrix * MCTangent.xyz);
+  // A flipped sprite mirrors its tangent space, see the sprite package's vertices.
+  float det = mv3Matrix[0][0] * mv3Matrix[1][1] - mv3Matrix[1][0] * mv3Matrix[0][1];
+  vec3 b = MCTangent.w * sign(det) * cross(n, t);
+
+  LightDir = vec3(ViewMatrix * vec4(LightPos, 0.0)) - vec3(ccVertex);
+  vec3 v;
+  v.x = dot(LightDir, t);
+  v.y = dot(LightDir, b);
+  v.z = dot(LightDir, n);
+  LightDir = v;
+
+  EyeDir = vec3(-ccVertex);
+  v.x = dot(EyeDir, t);
+  v.y = dot(EyeDir, b);
+  v.z = dot(EyeDir, n);
+  EyeDir = v;
+}
+` + "\x00"
+
+var coreFragmentShader = `
+uniform int AddColor;
+uniform vec4 AColor;
+uniform int SubColor;
+uniform vec4 SColor;
+uniform vec4 MColor;
+// Blend is the sprite.BlendMode the fragment is blended with, 2 is Multiply and 3
+// Screen.
+uniform int Blend;
+uniform sampler2D ColorMap;
+uniform sampler2D NormalMap;
+uniform vec4 AmbientColor;
+uniform vec3 LightPos;
+uniform vec4 LightColor;
+uniform float LightPower;
+uniform int UseShadowMap;
+uniform sampler2D ShadowMap;
+uniform vec2 ShadowTexel;
+uniform float ShadowHeight;
+uniform float ShadowStrength;
+// ShadowFrame is the frame being drawn, as its min and max texture coordinates.
+uniform vec4 ShadowFrame;
+
+in vec3 Pos;
+in vec3 LightDir;
+in vec3 EyeDir;
+in vec2 TexCoord;
+
+layout(location = 0) out vec4 FragColor;
+
+// Steps taken towards the light looking for parts of the shadow map which block it.
+const int shadowSteps = 16;
+
+// shadow returns how much of the light in direction l, in tangent space, reaches
+// the fragment past the heights of the shadow map.
+float shadow(vec3 l) {
+  if (UseShadowMap != 1 || l.z <= 0.0) {
+    return 1.0;
+  }
+  float height = texture(ShadowMap, TexCoord.st).r * ShadowHeight;
+  // Texels moved for each texel the ray rises.  T increases down the texture,
+  // tangent space y is up.
+  vec2 dir = vec2(l.x, -l.y) / max(l.z, 0.1) * ShadowTexel;
+  float rise = (ShadowHeight - height) / float(shadowSteps);
+  for (int i = 1; i <= shadowSteps; i++) {
+    float ray = rise * float(i);
+    vec2 st = TexCoord.st + dir * ray;
+    // Past the edge of the frame is another frame, or the other side of the
+    // texture, which can't block the light.
+    if (any(lessThan(st, ShadowFrame.xy)) || any(greaterThan(st, ShadowFrame.zw))) {
+      break;
+    }
+    if (texture(ShadowMap, st).r * ShadowHeight > height + ray) {
+      return 1.0 - ShadowStrength;
+    }
+  }
+  return 1.0;
+}
+
+void main() {
+  float alpha = texture(ColorMap, TexCoord.st).a;
+  if (alpha == 0.0) {
+    discard;
+  }
+  vec3 diffuse = texture(ColorMap, TexCoord.st).rgb;
+  if (AddColor == 1) {
+    diffuse = clamp(diffuse + AColor.rgb, 0.0, 1.0);
+  }
+  if (SubColor == 1) {
+    diffuse = clamp(diffuse - SColor.rgb, 0.0, 1.0);
+  }
+  diffuse *= MColor.rgb;
+  alpha *= MColor.a;
+  vec3 ambient = AmbientColor.rgb * diffuse;
+  vec3 specular = diffuse / 8.0;
+
+  vec3 normal = texture(NormalMap, TexCoord.st).rgb * 2.0 - 1.0;
+  float distance = length(LightPos - Pos);
+
+  vec3 n = normalize(normal);
+  vec3 l = normalize(LightDir);
+
+  float cosTheta = clamp(dot(n, l), 0.0, 1.0);
+
+  vec3 e = normalize(EyeDir);
+  vec3 r = reflect(-l, n);
+
+  float cosAlpha = clamp(dot(e, r), 0.0, 1.0);
+  float lit = shadow(l);
+
+  vec3 color = ambient +
+    lit * diffuse * LightColor.rgb * LightPower * cosTheta /
+      (distance * distance) +
+    lit * specular * LightColor.rgb * LightPower * pow(cosAlpha, 5.0) /
+      (distance * distance);
+  // Multiply and Screen blend by color only, so fade the color by alpha towards
+  // the one which leaves the destination unchanged, white and black.
+  if (Blend == 2) {
+    color = mix(vec3(1.0), color, alpha);
+  } else if (Blend == 3) {
+    color *= alpha;
+  }
+  FragColor = vec4(color, alpha);
+}
+` + "\x00"
@@ -0,0 +1,101 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/hurricanerix/shade/gpu"
+)
+
+// RenderTarget is a texture which can be drawn to instead of the screen.
+type RenderTarget struct {
+	Width       int
+	Height      int
+	framebuffer uint32
+	texture     uint32
+	depthbuffer uint32
+}
+
+// NewRenderTarget returns a width x height target with a color texture and a
+// depth buffer.
+func NewRenderTarget(width, height int) (*RenderTarget, error) {
+	t := RenderTarget{
+		Width:  width,
+		Height: height,
+	}
+
+	gl.GenFramebuffers(1, &t.framebuffer)
+	gpu.Created(gpu.Framebuffer, t.framebuffer)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.framebuffer)
+
+	gl.GenTextures(1, &t.texture)
+	gpu.Created(gpu.Texture, t.texture)
+	gl.BindTexture(gl.TEXTURE_2D, t.texture)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, t.texture, 0)
+
+	gl.GenRenderbuffers(1, &t.depthbuffer)
+	gpu.Created(gpu.Renderbuffer, t.depthbuffer)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, t.depthbuffer)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(width), int32(height))
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, t.depthbuffer)
+
+	if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+		t.Delete()
+		return nil, fmt.Errorf("render target is incomplete: 0x%x", status)
+	}
+	return &t, nil
+}
+
+// Bind the target so following draw calls render into it.  Use
+// display.Context.BindScreen to go back to drawing to the screen.
+func (t *RenderTarget) Bind() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.framebuffer)
+	gl.Viewport(0, 0, int32(t.Width), int32(t.Height))
+}
+
+// Texture returns the OpenGL texture holding what was drawn to the target.
+func (t *RenderTarget) Texture() uint32 {
+	return t.texture
+}
+
+// Delete the target's OpenGL objects.
+func (t *RenderTarget) Delete() {
+	gl.DeleteFramebuffers(1, &t.framebuffer)
+	gpu.Deleted(gpu.Framebuffer, t.framebuffer)
+	gl.DeleteTextures(1, &t.texture)
+	gpu.Deleted(gpu.Texture, t.texture)
+	gl.DeleteRenderbuffers(1, &t.depthbuffer)
+	gpu.Deleted(gpu.Renderbuffer, t.depthbuffer)
+	t.framebuffer, t.texture, t.depthbuffer = 0, 0, 0
+}
+
+// BindScreen so following draw calls render to the screen again after drawing to a
+// RenderTarget.  When post processing is enabled the screen is the texture the
+// passes are applied to.
+func (c *Context) BindScreen() {
+	if c.scene != nil {
+		c.scene.Bind()
+		return
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, c.framebuffer)
+	gl.Viewport(c.viewport[0], c.viewport[1], c.viewport[2], c.viewport[3])
+}
@@ -0,0 +1,231 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/hurricanerix/shade/events"
+)
+
+// WindowMode controls how the window is shown.
+type WindowMode int
+
+const (
+	// Windowed shows a normal window with decorations.
+	Windowed WindowMode = iota
+	// Fullscreen changes the monitor's video mode to the requested size.
+	Fullscreen
+	// Borderless covers the whole monitor at its current video mode.
+	Borderless
+)
+
+// Scaling controls how a virtual resolution is fit to the window.
+type Scaling int
+
+const (
+	// Letterbox scales uniformly to fit the window, leaving bars on the sides.
+	Letterbox Scaling = iota
+	// Stretch scales to fill the whole window, ignoring the aspect ratio.
+	Stretch
+	// PixelPerfect scales uniformly by whole numbers only, leaving bars on the sides.
+	PixelPerfect
+)
+
+// Options for creating a display with SetModeOptions.
+type Options struct {
+	// Mode the window is created in.
+	Mode WindowMode
+	// Monitor used for Fullscreen and Borderless modes, as an index into
+	// glfw.GetMonitors().  0 is the primary monitor.
+	Monitor int
+	// Resizable allows the user to resize a Windowed window.
+	Resizable bool
+	// VirtualWidth is the logical width of the screen.  If VirtualWidth and
+	// VirtualHeight are set, the game always sees this resolution and it is
+	// scaled to fit the window.
+	VirtualWidth int
+	// VirtualHeight is the logical height of the screen.
+	VirtualHeight int
+	// Scaling used to fit the virtual resolution to the window.
+	Scaling Scaling
+	// Offscreen renders into a framebuffer object of a hidden window,
+	// see SetModeOffscreen.
+	Offscreen bool
+	// Debug tracks the OpenGL objects created through shade and reports those which
+	// are still alive when Terminate is called, see the gpu package.
+	Debug bool
+}
+
+func getMonitor(i int) (*glfw.Monitor, error) {
+	monitors := glfw.GetMonitors()
+	if i < 0 || i >= len(monitors) {
+		return nil, fmt.Errorf("monitor %d not found, %d connected", i, len(monitors))
+	}
+	return monitors[i], nil
+}
+
+// SetWindowMode switches the window to mode on monitor (see Options.Monitor) while
+// the game is running.  Switching back to Windowed restores the window's last
+// windowed position and size.
+func (c *Context) SetWindowMode(mode WindowMode, monitor int) error {
+	if c.Offscreen {
+		return fmt.Errorf("can not change window mode of an offscreen display")
+	}
+	if mode == c.Mode {
+		return nil
+	}
+
+	if c.Mode == Windowed {
+		x, y := c.Window.GetPos()
+		w, h := c.Window.GetSize()
+		c.windowed = [4]int{x, y, w, h}
+	}
+
+	switch mode {
+	case Windowed:
+		x, y := c.windowed[0], c.windowed[1]
+		if x < 0 || y < 0 {
+			// Window was never windowed, so there is no position to restore, put it
+			// near the top left of the screen.
+			x, y = 100, 100
+		}
+		c.Window.SetMonitor(nil, x, y, c.windowed[2], c.windowed[3], 0)
+	case Fullscreen, Borderless:
+		m, err := getMonitor(monitor)
+		if err != nil {
+			return err
+		}
+		w, h := c.windowed[2], c.windowed[3]
+		vm := m.GetVideoMode()
+		if mode == Borderless {
+			w, h = vm.Width, vm.Height
+		}
+		c.Window.SetMonitor(m, 0, 0, w, h, vm.RefreshRate)
+	default:
+		return fmt.Errorf("unknown window mode %d", mode)
+	}
+
+	c.Mode = mode
+	c.options.Monitor = monitor
+	return nil
+}
+
+// ToggleFullscreen switches between Windowed and Borderless on the current monitor.
+func (c *Context) ToggleFullscreen() error {
+	if c.Mode == Windowed {
+		return c.SetWindowMode(Borderless, c.options.Monitor)
+	}
+	return c.SetWindowMode(Windowed, c.options.Monitor)
+}
+
+// Viewport returns the area of the framebuffer the scene is drawn to in pixels.
+func (c *Context) Viewport() (x, y, width, height int32) {
+	return c.viewport[0], c.viewport[1], c.viewport[2], c.viewport[3]
+}
+
+// ToScreen converts a position in window coordinates with the origin at the bottom
+// left, as reported by cursor events, to a position on the screen.  Without a
+// virtual resolution this only accounts for the framebuffer's pixel density.
+func (c *Context) ToScreen(x, y float32) (float32, float32) {
+	ww, wh := c.Window.GetSize()
+	fw, fh := c.Window.GetFramebufferSize()
+	if ww == 0 || wh == 0 || c.viewport[2] == 0 || c.viewport[3] == 0 {
+		return x, y
+	}
+	x = x*float32(fw)/float32(ww) - float32(c.viewport[0])
+	y = y*float32(fh)/float32(wh) - float32(c.viewport[1])
+	return x * c.Width / float32(c.viewport[2]), y * c.Height / float32(c.viewport[3])
+}
+
+func (c *Context) framebufferSizeCallback(w *glfw.Window, width, height int) {
+	c.resize(width, height)
+	events.FramebufferSizeCallback(w, width, height)
+}
+
+// Resizer is resized with a screen, see AddResizer.
+type Resizer interface {
+	Resize(width, height float32)
+}
+
+// AddResizer so it is resized to the screen's new Width and Height after its window
+// is resized.  Screens with a virtual resolution keep their size, so r is not
+// resized.
+func (c *Context) AddResizer(r Resizer) {
+	c.resizers = append(c.resizers, r)
+}
+
+// RemoveResizer added by AddResizer.
+func (c *Context) RemoveResizer(r Resizer) {
+	for i := range c.resizers {
+		if c.resizers[i] == r {
+			c.resizers = append(c.resizers[:i], c.resizers[i+1:]...)
+			return
+		}
+	}
+}
+
+// resize the viewport to fit a framebuffer of width x height pixels.
+func (c *Context) resize(width, height int) {
+	if width == 0 || height == 0 {
+		// Minimized
+		return
+	}
+
+	if c.options.VirtualWidth <= 0 || c.options.VirtualHeight <= 0 {
+		ww, wh := c.Window.GetSize()
+		c.Width = float32(ww)
+		c.Height = float32(wh)
+		c.setViewport(0, 0, width, height)
+		for _, r := range c.resizers {
+			r.Resize(c.Width, c.Height)
+		}
+		return
+	}
+
+	vw, vh := c.options.VirtualWidth, c.options.VirtualHeight
+	if c.options.Scaling == Stretch {
+		c.setViewport(0, 0, width, height)
+		return
+	}
+
+	scale := float32(width) / float32(vw)
+	if s := float32(height) / float32(vh); s < scale {
+		scale = s
+	}
+	if c.options.Scaling == PixelPerfect && scale >= 1 {
+		scale = float32(int(scale))
+	}
+	w := int(float32(vw) * scale)
+	h := int(float32(vh) * scale)
+	c.setViewport((width-w)/2, (height-h)/2, w, h)
+}
+
+func (c *Context) setViewport(x, y, width, height int) {
+	c.viewport = [4]int32{int32(x), int32(y), int32(width), int32(height)}
+	if len(c.passes) > 0 {
+		err := c.createTargets()
+		if err == nil {
+			return
+		}
+		fmt.Println("Warning: could not resize post processing, turning it off:", err)
+		c.passes = nil
+		c.BindScreen()
+		return
+	}
+	gl.Viewport(c.viewport[0], c.viewport[1], c.viewport[2], c.viewport[3])
+}
@@ -0,0 +1,40 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entity
+
+import "sort"
+
+// ByDepth sorts Drawers back to front by the Z of their Pos, lowest Z first.
+type ByDepth []Drawer
+
+func (a ByDepth) Len() int           { return len(a) }
+func (a ByDepth) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a ByDepth) Less(i, j int) bool { return a[i].Pos()[2] < a[j].Pos()[2] }
+
+// Draw all Drawers in group back to front, so those with a higher Z are drawn on
+// top of those with a lower Z and translucent sprites blend with what is behind
+// them.  Drawers with the same Z are drawn in the order they appear in group.
+func Draw(group []Entity) {
+	var drawers []Drawer
+	for _, e := range group {
+		if d, ok := e.(Drawer); ok {
+			drawers = append(drawers, d)
+		}
+	}
+	sort.Stable(ByDepth(drawers))
+	for _, d := range drawers {
+		d.Draw()
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entity
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type layer struct {
+	name  string
+	z     float32
+	drawn *[]string
+}
+
+func (l layer) Pos() mgl32.Vec3 {
+	return mgl32.Vec3{0.0, 0.0, l.z}
+}
+
+func (l layer) Draw() {
+	*l.drawn = append(*l.drawn, l.name)
+}
+
+func TestDrawByDepth(t *testing.T) {
+	var drawn []string
+	group := []Entity{
+		layer{"front", 2.0, &drawn},
+		"not a drawer",
+		layer{"back", -1.0, &drawn},
+		layer{"middle1", 0.0, &drawn},
+		layer{"middle2", 0.0, &drawn},
+	}
+
+	Draw(group)
+
+	expected := []string{"back", "middle1", "middle2", "front"}
+	if len(drawn) != len(expected) {
+		t.Fatalf("Expected %d entities to be drawn but found %d", len(expected), len(drawn))
+	}
+	for i := range expected {
+		if drawn[i] != expected[i] {
+			t.Error("Expected draw order", expected, "but found", drawn)
+			break
+		}
+	}
+}
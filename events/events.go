@@ -18,7 +18,7 @@ package events
 import (
 	"runtime"
 
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.2/glfw"
 )
 
 func init() {
@@ -39,6 +39,9 @@ const (
 	WindowClose     = iota
 	MouseButtonDown = iota
 	MouseButtonUp   = iota
+	// FramebufferResize is sent when the window's framebuffer changes size, Width and
+	// Height hold the new size in pixels.
+	FramebufferResize = iota
 )
 
 type Event struct {
@@ -50,6 +53,8 @@ type Event struct {
 	X           float32
 	Y           float32
 	MouseButton glfw.MouseButton
+	Width       int
+	Height      int
 }
 
 var events []Event
@@ -67,6 +72,8 @@ func Get() []Event {
 			X:           events[i].X,
 			Y:           events[i].Y,
 			MouseButton: events[i].MouseButton,
+			Width:       events[i].Width,
+			Height:      events[i].Height,
 		})
 	}
 	events = nil
@@ -112,6 +119,16 @@ func WindowCloseCallback(w *glfw.Window) {
 	})
 }
 
+// FramebufferSizeCallback TODO doc
+func FramebufferSizeCallback(w *glfw.Window, width, height int) {
+	events = append(events, Event{
+		Type:   FramebufferResize,
+		Window: w,
+		Width:  width,
+		Height: height,
+	})
+}
+
 // MouseButtonCallback  TODO: doc
 func MouseButtonCallback(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
 	e := Event{
@@ -23,7 +23,7 @@ import (
 	"runtime"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/hurricanerix/shade/camera"
 	"github.com/hurricanerix/shade/display"
@@ -44,8 +44,9 @@ func main() {
 	if err != nil {
 		log.Fatalln("failed to set display mode:", err)
 	}
+	defer screen.Terminate()
 
-	cam, err := camera.New()
+	cam, err := camera.New(screen)
 	if err != nil {
 		panic(err)
 	}
@@ -56,6 +57,7 @@ func main() {
 		panic(err)
 	}
 	s.Bind(screen.Program)
+	defer s.Release()
 
 	for running := true; running; {
 		screen.Fill(0.0, 0.0, 0.0)
@@ -20,14 +20,13 @@ import (
 	"log"
 	"runtime"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade"
 	"github.com/hurricanerix/shade/camera"
 	"github.com/hurricanerix/shade/display"
 	"github.com/hurricanerix/shade/events"
 	"github.com/hurricanerix/shade/sprite"
-	"github.com/hurricanerix/shade/time/clock"
 )
 
 const windowWidth = 640
@@ -43,61 +42,53 @@ func main() {
 	if err != nil {
 		log.Fatalln("failed to set display mode:", err)
 	}
+	defer screen.Terminate()
 
-	cam, err := camera.New()
+	cam, err := camera.New(screen)
 	if err != nil {
 		panic(err)
 	}
 	cam.Bind(screen.Program)
 
-	clock, err := clock.New()
-	if err != nil {
-		panic(err)
-	}
-
 	a, err := loadSprite("animation.png", 3, 1)
 	if err != nil {
 		panic(err)
 	}
 	a.Bind(screen.Program)
-	var aframe float32 = 0.0
-
-	for running := true; running; {
-		dt := clock.Tick(30)
-		screen.Fill(200.0/256.0, 200/256.0, 200/256.0)
-
-		// TODO move this somewhere else (maybe a Clear method of display
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		for _, event := range events.Get() {
-			if event.Type == events.KeyUp && event.Key == glfw.KeyEscape {
-				// Send window close event
-				screen.Close()
-			}
-			if event.Type == events.WindowClose {
-				// Handle window close
-				running = false
-			}
-		}
+	anim := sprite.NewAnimator(sprite.NewAnimation("spin", sprite.Loop, 333,
+		mgl32.Vec2{0, 0}, mgl32.Vec2{1, 0}, mgl32.Vec2{2, 0}))
 
-		aframe += 0.003 * dt
-		if int(aframe) > 2 {
-			aframe = 0
-		}
+	g := game{
+		screen: screen,
+		sprite: a,
+		anim:   anim,
+	}
+	shade.RunOptions(screen, &g, shade.Options{Background: [3]float32{200.0 / 256.0, 200 / 256.0, 200 / 256.0}})
+}
 
-		frame := mgl32.Vec2{
-			float32(int(aframe)), // Truncate to correct frame
-			0}
-		pos := mgl32.Vec3{
-			windowWidth/2 - float32(a.Width)/2,
-			windowHeight/2 - float32(a.Height)/2,
-			0}
-		a.DrawFrame(frame, pos, nil)
+type game struct {
+	screen *display.Context
+	sprite *sprite.Context
+	anim   *sprite.Animator
+}
 
-		screen.Flip()
-		events.Poll()
+func (g *game) HandleEvent(event events.Event) {
+	if event.Type == events.KeyUp && event.Key == glfw.KeyEscape {
+		// Send window close event
+		g.screen.Close()
 	}
+}
+
+func (g *game) Update(dt float32) {
+	g.anim.Update(dt)
+}
 
+func (g *game) Draw(alpha float32) {
+	pos := mgl32.Vec3{
+		windowWidth/2 - float32(g.sprite.Width)/2,
+		windowHeight/2 - float32(g.sprite.Height)/2,
+		0}
+	g.sprite.DrawFrame(g.anim.Frame(), pos, nil)
 }
 
 func loadSprite(path string, framesWide, framesHigh int) (*sprite.Context, error) {
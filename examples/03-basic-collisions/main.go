@@ -20,12 +20,11 @@ import (
 	"log"
 	"runtime"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade"
 	"github.com/hurricanerix/shade/camera"
 	"github.com/hurricanerix/shade/display"
-	"github.com/hurricanerix/shade/entity"
 	"github.com/hurricanerix/shade/events"
 	"github.com/hurricanerix/shade/examples/03-basic-collisions/ball"
 	"github.com/hurricanerix/shade/examples/03-basic-collisions/block"
@@ -33,7 +32,6 @@ import (
 	"github.com/hurricanerix/shade/fonts"
 	"github.com/hurricanerix/shade/shapes"
 	"github.com/hurricanerix/shade/sprite"
-	"github.com/hurricanerix/shade/time/clock"
 )
 
 const windowWidth = 640
@@ -50,8 +48,9 @@ func main() {
 	if err != nil {
 		log.Fatalln("failed to set display mode:", err)
 	}
+	defer screen.Terminate()
 
-	cam, err := camera.New()
+	cam, err := camera.New(screen)
 	if err != nil {
 		panic(err)
 	}
@@ -63,26 +62,21 @@ func main() {
 	}
 	font.Bind(screen.Program)
 
-	clock, err := clock.New()
-	if err != nil {
-		panic(err)
-	}
-
-	objects := []entity.Entity{}
+	objects := sprite.NewGroup()
 
 	blockSprite, err := loadSprite("assets/block32x32.png", "", 2, 1)
 	if err != nil {
 		panic(err)
 	}
 	blockSprite.Bind(screen.Program)
-	objects = append(objects, block.New(0, float32(windowWidth)/6, float32(windowHeight)/2, blockSprite, *font))
+	objects.Add(block.New(0, float32(windowWidth)/6, float32(windowHeight)/2, blockSprite, *font))
 
 	ballSprite, err := loadSprite("assets/ball.png", "", 1, 1)
 	if err != nil {
 		panic(err)
 	}
 	ballSprite.Bind(screen.Program)
-	objects = append(objects, ball.New(float32(windowWidth)/2, float32(windowHeight)/2, ballSprite, *font))
+	objects.Add(ball.New(float32(windowWidth)/2, float32(windowHeight)/2, ballSprite, *font))
 
 	//shapes.NewCircle(mgl32.Vec2{float32(s.Width) / 2, float32(s.Height) / 2}, float32(s.Width)/2),
 	tmpSprites := []sprite.Context{*blockSprite, *ballSprite}
@@ -91,42 +85,36 @@ func main() {
 		*shapes.NewCircle(mgl32.Vec2{float32(ballSprite.Width) / 2, float32(ballSprite.Height) / 2}, float32(ballSprite.Width)/2),
 	}
 	pl := player.New(0, 0, tmpSprites, tmpShapes, *font)
-	objects = append(objects, &pl)
-
-	for running := true; running; {
-		dt := clock.Tick(30)
-
-		screen.Fill(0.3, 0.3, 0.6)
-
-		// TODO move this somewhere else (maybe a Clear method of display
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	objects.Add(&pl)
 
-		for _, event := range events.Get() {
-			if event.Type == events.KeyUp && event.Key == glfw.KeyEscape {
-				// Send window close event
-				screen.Close()
-			}
-			if event.Type == events.WindowClose {
-				// Handle window close
-				running = false
-			}
+	g := game{
+		screen:  screen,
+		objects: objects,
+		player:  &pl,
+	}
+	shade.RunOptions(screen, &g, shade.Options{Background: [3]float32{0.3, 0.3, 0.6}})
+}
 
-			pl.Handle(event)
-		}
+type game struct {
+	screen  *display.Context
+	objects *sprite.Group
+	player  *player.Player
+}
 
-		for _, e := range objects {
-			if u, ok := e.(entity.Updater); ok {
-				u.Update(dt, &objects)
-			}
-			if d, ok := e.(entity.Drawer); ok {
-				d.Draw()
-			}
-		}
+func (g *game) HandleEvent(event events.Event) {
+	if event.Type == events.KeyUp && event.Key == glfw.KeyEscape {
+		// Send window close event
+		g.screen.Close()
+	}
+	g.player.Handle(event)
+}
 
-		screen.Flip()
-		events.Poll()
+func (g *game) Update(dt float32) {
+	g.objects.Update(dt)
+}
 
-	}
+func (g *game) Draw(alpha float32) {
+	g.objects.Draw()
 }
 
 func loadSprite(colorName, normalName string, framesWide, framesHigh int) (*sprite.Context, error) {
@@ -19,7 +19,7 @@ import (
 	"fmt"
 	"runtime"
 
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/hurricanerix/shade/entity"
 	"github.com/hurricanerix/shade/events"
@@ -92,16 +92,10 @@ func (p Player) Bounds() shapes.Shape {
 }
 
 // Update TODO doc
-func (p *Player) Update(dt float32, group *[]entity.Entity) {
+func (p *Player) Update(dt float32, group *sprite.Group) {
 	p.Collision = nil
 	p.With = ""
-	var cgroup []entity.Collider
-	for i := range *group {
-		if c, ok := (*group)[i].(entity.Collider); ok {
-			cgroup = append(cgroup, c)
-		}
-	}
-	for _, c := range entity.Collide(p, &cgroup, false) {
+	for _, c := range group.Collide(p, false) {
 		p.Collision = &c
 	}
 }
@@ -20,7 +20,6 @@ import (
 	"runtime"
 
 	"github.com/go-gl/mathgl/mgl32"
-	"github.com/hurricanerix/shade/entity"
 	"github.com/hurricanerix/shade/shapes"
 	"github.com/hurricanerix/shade/sprite"
 )
@@ -37,6 +36,10 @@ type Ball struct {
 	Shape  shapes.Shape
 	dx     float32
 	dy     float32
+	// prev is the position before the last Update, and drawn is where the ball is
+	// drawn between it and pos, see Interpolate.
+	prev  mgl32.Vec3
+	drawn mgl32.Vec3
 }
 
 // New TODO doc
@@ -47,6 +50,7 @@ func New(x, y, speed, angle float32, s *sprite.Context) Ball {
 		Sprite: s,
 		Shape:  *shapes.NewCircle(mgl32.Vec2{float32(s.Width) / 2, float32(s.Width) / 2}, float32(s.Width)/2),
 	}
+	b.prev, b.drawn = b.pos, b.pos
 	b.dx = float32(math.Cos(float64(angle))) * speed
 	b.dy = float32(math.Sin(float64(angle))) * speed
 	return b
@@ -66,22 +70,16 @@ func (b Ball) Pos() mgl32.Vec3 {
 }
 
 // Update TODO doc
-func (b *Ball) Update(dt float32, group *[]entity.Entity) {
+func (b *Ball) Update(dt float32, group *sprite.Group) {
 	lastPos := mgl32.Vec3{b.pos[0], b.pos[1], b.pos[2]}
+	b.prev = lastPos
 	switchDx := false
 	switchDy := false
 
 	b.pos[0] += b.dx * dt
 	b.pos[1] += b.dy * dt
 
-	var cgroup []entity.Collider
-	for i := range *group {
-		if c, ok := (*group)[i].(entity.Collider); ok {
-			cgroup = append(cgroup, c)
-		}
-	}
-
-	for _, c := range entity.Collide(b, &cgroup, false) {
+	for _, c := range group.Collide(b, false) {
 
 		eb := c.Hit.Bounds()
 		//ep := c.Hit.Pos()
@@ -116,7 +114,13 @@ func (b *Ball) Update(dt float32, group *[]entity.Entity) {
 	}
 }
 
+// Interpolate where the ball is drawn, alpha of the way from its position before the
+// last Update to its current one, so it moves smoothly between updates.
+func (b *Ball) Interpolate(alpha float32) {
+	b.drawn = b.prev.Add(b.pos.Sub(b.prev).Mul(alpha))
+}
+
 // Draw TODO doc
 func (b Ball) Draw() {
-	b.Sprite.Draw(b.pos, nil)
+	b.Sprite.Draw(b.drawn, nil)
 }
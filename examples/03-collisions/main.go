@@ -22,16 +22,14 @@ import (
 	"runtime"
 	"time"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/hurricanerix/shade"
 	"github.com/hurricanerix/shade/camera"
 	"github.com/hurricanerix/shade/display"
-	"github.com/hurricanerix/shade/entity"
 	"github.com/hurricanerix/shade/events"
 	"github.com/hurricanerix/shade/examples/03-collisions/ball"
 	"github.com/hurricanerix/shade/examples/03-collisions/block"
 	"github.com/hurricanerix/shade/sprite"
-	"github.com/hurricanerix/shade/time/clock"
 )
 
 const windowWidth = 640
@@ -47,19 +45,15 @@ func main() {
 	if err != nil {
 		log.Fatalln("failed to set display mode:", err)
 	}
+	defer screen.Terminate()
 
-	cam, err := camera.New()
+	cam, err := camera.New(screen)
 	if err != nil {
 		panic(err)
 	}
 	cam.Bind(screen.Program)
 
-	clock, err := clock.New()
-	if err != nil {
-		panic(err)
-	}
-
-	objects := []entity.Entity{}
+	objects := sprite.NewGroup()
 
 	blockSprite, err := loadSprite("assets/block32x32.png", "", 2, 1)
 	if err != nil {
@@ -70,14 +64,14 @@ func main() {
 	for x := 0; float32(x) < screen.Width; x += 32 {
 		for y := 0; float32(y) < screen.Height; y += 32 {
 			if x == 0 || x == 640-32 || y == 0 || y == 480-32 {
-				objects = append(objects, block.New(float32(x), float32(y), blockSprite))
+				objects.Add(block.New(float32(x), float32(y), blockSprite))
 			}
 		}
 	}
-	objects = append(objects, block.New(float32(blockSprite.Width)*4, float32(blockSprite.Height)*4, blockSprite))
-	objects = append(objects, block.New(float32(blockSprite.Width)*4, windowHeight-float32(blockSprite.Height)*5, blockSprite))
-	objects = append(objects, block.New(windowWidth-float32(blockSprite.Width)*5, float32(blockSprite.Height)*4, blockSprite))
-	objects = append(objects, block.New(windowWidth-float32(blockSprite.Width)*5, windowHeight-float32(blockSprite.Height)*5, blockSprite))
+	objects.Add(block.New(float32(blockSprite.Width)*4, float32(blockSprite.Height)*4, blockSprite))
+	objects.Add(block.New(float32(blockSprite.Width)*4, windowHeight-float32(blockSprite.Height)*5, blockSprite))
+	objects.Add(block.New(windowWidth-float32(blockSprite.Width)*5, float32(blockSprite.Height)*4, blockSprite))
+	objects.Add(block.New(windowWidth-float32(blockSprite.Width)*5, windowHeight-float32(blockSprite.Height)*5, blockSprite))
 
 	ballSprite, err := loadSprite("assets/ball.png", "", 1, 1)
 	if err != nil {
@@ -88,43 +82,48 @@ func main() {
 	rand.Seed(time.Now().Unix())
 	//rand.Seed(1)
 
-	objects = append(objects, addBall(screen.Width/2, screen.Height/2, ballSprite))
+	objects.Add(addBall(screen.Width/2, screen.Height/2, ballSprite))
+
+	g := game{
+		screen:     screen,
+		objects:    objects,
+		ballSprite: ballSprite,
+	}
+	shade.Run(screen, &g)
+}
 
-	//	sprites.Bind(screen.Program)
-	for running := true; running; {
-		dt := clock.Tick(30)
+type game struct {
+	screen     *display.Context
+	objects    *sprite.Group
+	ballSprite *sprite.Context
+}
 
-		screen.Fill(0.0, 0.0, 0.0)
+func (g *game) HandleEvent(event events.Event) {
+	if event.Type == events.KeyUp && event.Key == glfw.KeyEscape {
+		// Send window close event
+		g.screen.Close()
+	}
+	if (event.Type == events.KeyDown || event.Type == events.KeyRepeat) && event.Key == glfw.KeySpace {
+		g.objects.Add(addBall(g.screen.Width/2, g.screen.Height/2, g.ballSprite))
+	}
+}
 
-		// TODO move this somewhere else (maybe a Clear method of display
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+func (g *game) Update(dt float32) {
+	g.objects.Update(dt)
+}
 
-		for _, event := range events.Get() {
-			if event.Type == events.KeyUp && event.Key == glfw.KeyEscape {
-				// Send window close event
-				screen.Close()
-			}
-			if event.Type == events.WindowClose {
-				// Handle window close
-				running = false
-			}
-			if (event.Type == events.KeyDown || event.Type == events.KeyRepeat) && event.Key == glfw.KeySpace {
-				objects = append(objects, addBall(screen.Width/2, screen.Height/2, ballSprite))
-			}
-		}
+// interpolator is drawn between its positions before and after the last update.
+type interpolator interface {
+	Interpolate(alpha float32)
+}
 
-		for _, e := range objects {
-			if u, ok := e.(entity.Updater); ok {
-				u.Update(dt/1000, &objects)
-			}
-			if d, ok := e.(entity.Drawer); ok {
-				d.Draw()
-			}
+func (g *game) Draw(alpha float32) {
+	for _, e := range g.objects.Entities() {
+		if i, ok := e.(interpolator); ok {
+			i.Interpolate(alpha)
 		}
-
-		screen.Flip()
-		events.Poll()
 	}
+	g.objects.Draw()
 }
 
 func addBall(x, y float32, s *sprite.Context) *ball.Ball {
@@ -18,7 +18,6 @@ package ball
 
 import (
 	"github.com/go-gl/mathgl/mgl32"
-	"github.com/hurricanerix/shade/entity"
 	"github.com/hurricanerix/shade/examples/ex1-pong/player"
 	"github.com/hurricanerix/shade/sprite"
 )
@@ -43,7 +42,7 @@ func (b Ball) Pos() mgl32.Vec3 {
 	return b.pos
 }
 
-func (b *Ball) Update(dt float32, g *[]entity.Entity) {
+func (b *Ball) Update(dt float32, g *sprite.Group) {
 }
 
 func (b Ball) Draw() {
@@ -19,18 +19,16 @@ import (
 	"fmt"
 	"runtime"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade"
 	"github.com/hurricanerix/shade/camera"
 	"github.com/hurricanerix/shade/display"
-	"github.com/hurricanerix/shade/entity"
 	"github.com/hurricanerix/shade/events"
 	"github.com/hurricanerix/shade/examples/ex1-pong/ball"
 	"github.com/hurricanerix/shade/examples/ex1-pong/player"
 	"github.com/hurricanerix/shade/fonts"
 	"github.com/hurricanerix/shade/sprite"
-	"github.com/hurricanerix/shade/time/clock"
 )
 
 func init() {
@@ -57,17 +55,12 @@ func New(screen *display.Context) (Context, error) {
 
 // Main TODO doc
 func (c *Context) Main(screen *display.Context, config Config) {
-	cam, err := camera.New()
+	cam, err := camera.New(screen)
 	if err != nil {
 		panic(err)
 	}
 	cam.Bind(c.Screen.Program)
 
-	clock, err := clock.New()
-	if err != nil {
-		panic(err)
-	}
-
 	paddleSprite, err := loadSpriteAsset("assets/paddle.png", "", 1, 3)
 	if err != nil {
 		panic(err)
@@ -80,13 +73,10 @@ func (c *Context) Main(screen *display.Context, config Config) {
 	}
 	ballSprite.Bind(screen.Program)
 
-	var objects []entity.Entity
 	player1 := player.New(cam.Left+15, screen.Height/4, paddleSprite)
-	objects = append(objects, player1)
 	player2 := player.New(cam.Right-15, screen.Height/4, paddleSprite)
-	objects = append(objects, player2)
 	ball := ball.New(mgl32.Vec3{screen.Width / 4, screen.Height / 2, 0.0}, mgl32.Vec3{0, 1, 0}, player1, ballSprite)
-	objects = append(objects, ball)
+	objects := sprite.NewGroup(player1, player2, ball)
 
 	font, err := fonts.SimpleASCII()
 	if err != nil {
@@ -94,50 +84,59 @@ func (c *Context) Main(screen *display.Context, config Config) {
 	}
 	font.Bind(screen.Program)
 
-	for running := true; running; {
-
-		screen.Fill(0, 0, 0)
+	shade.Run(screen, &play{
+		screen:  screen,
+		config:  config,
+		cam:     cam,
+		objects: objects,
+		player1: player1,
+		player2: player2,
+		ball:    ball,
+		font:    font,
+	})
+}
 
-		dt := clock.Tick(30)
+// play is the game being run by shade.Run.
+type play struct {
+	screen  *display.Context
+	config  Config
+	cam     *camera.Context
+	objects *sprite.Group
+	player1 *player.Player
+	player2 *player.Player
+	ball    *ball.Ball
+	font    *fonts.Context
+}
 
-		// TODO move this somewhere else (maybe a Clear method of display
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+// HandleEvent closes the window on escape.
+func (g *play) HandleEvent(event events.Event) {
+	if event.Type == events.KeyUp && event.Key == glfw.KeyEscape {
+		// Send window close event
+		g.screen.Close()
+	}
+}
 
-		for _, event := range events.Get() {
-			if event.Type == events.KeyUp && event.Key == glfw.KeyEscape {
-				// Send window close event
-				screen.Close()
-			}
-			if event.Type == events.WindowClose {
-				// Handle window close
-				running = false
-			}
-		}
+// Update the paddles and ball.
+func (g *play) Update(dt float32) {
+	g.objects.Update(dt)
+}
 
-		for _, e := range objects {
-			if u, ok := e.(entity.Updater); ok {
-				u.Update(dt, &objects)
-			}
-			if d, ok := e.(entity.Drawer); ok {
-				d.Draw()
-			}
-		}
+// Draw the paddles and ball, and the dev mode text if enabled.
+func (g *play) Draw(alpha float32) {
+	g.objects.Draw()
 
-		if config.DevMode {
-			deveff := sprite.Effects{
-				EnableLighting: false,
-				Scale:          mgl32.Vec3{2.0, 2.0, 1.0},
-				Tint:           mgl32.Vec4{1.0, 1.0, 1.0, 1.0},
-			}
-			msg := "Dev Mode!\n"
-			msg += fmt.Sprintf("Player1: %v\n", player1.Pos())
-			msg += fmt.Sprintf("Player2: %v\n", player2.Pos())
-			msg += fmt.Sprintf("Ball: %v\n", ball.Pos())
-			msg += fmt.Sprintf("Owner: %v\n", ball.Owner)
-			font.DrawText(mgl32.Vec3{cam.Left + 20, cam.Top - 40, 0}, &deveff, msg)
+	if g.config.DevMode {
+		deveff := sprite.Effects{
+			EnableLighting: false,
+			Scale:          mgl32.Vec3{2.0, 2.0, 1.0},
+			Tint:           mgl32.Vec4{1.0, 1.0, 1.0, 1.0},
 		}
-		screen.Flip()
-		events.Poll()
+		msg := "Dev Mode!\n"
+		msg += fmt.Sprintf("Player1: %v\n", g.player1.Pos())
+		msg += fmt.Sprintf("Player2: %v\n", g.player2.Pos())
+		msg += fmt.Sprintf("Ball: %v\n", g.ball.Pos())
+		msg += fmt.Sprintf("Owner: %v\n", g.ball.Owner)
+		g.font.DrawText(mgl32.Vec3{g.cam.Left + 20, g.cam.Top - 40, 0}, &deveff, msg)
 	}
 }
 
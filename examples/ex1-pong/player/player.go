@@ -18,7 +18,6 @@ package player
 
 import (
 	"github.com/go-gl/mathgl/mgl32"
-	"github.com/hurricanerix/shade/entity"
 	"github.com/hurricanerix/shade/sprite"
 )
 
@@ -41,7 +40,7 @@ func (p Player) Pos() mgl32.Vec3 {
 	return p.pos
 }
 
-func (p *Player) Update(dt float32, group *[]entity.Entity) {
+func (p *Player) Update(dt float32, group *sprite.Group) {
 }
 
 func (p Player) Draw() {
@@ -21,18 +21,16 @@ import (
 	"os"
 	"runtime"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade"
 	"github.com/hurricanerix/shade/camera"
 	"github.com/hurricanerix/shade/display"
-	"github.com/hurricanerix/shade/entity"
 	"github.com/hurricanerix/shade/events"
 	"github.com/hurricanerix/shade/examples/ex2-platform/block"
 	"github.com/hurricanerix/shade/examples/ex2-platform/player"
 	"github.com/hurricanerix/shade/fonts"
 	"github.com/hurricanerix/shade/sprite"
-	"github.com/hurricanerix/shade/time/clock"
 )
 
 func init() {
@@ -60,13 +58,13 @@ func New(screen *display.Context) (Context, error) {
 type Scene struct {
 	Sprites []sprite.Sprite
 	Player  *player.Player
-	Objects []entity.Entity
+	Objects *sprite.Group
 	//Walls   []entity.Collider
 }
 
 // Main TODO doc
 func (c *Context) Main(screen *display.Context, config Config) {
-	cam, err := camera.New()
+	cam, err := camera.New(screen)
 	if err != nil {
 		panic(err)
 	}
@@ -82,13 +80,19 @@ func (c *Context) Main(screen *display.Context, config Config) {
 	}
 	cam.Move(scene.Player.Pos())
 
-	clock, err := clock.New()
+	// Draw the map's blocks and the text with a few draw calls instead of one for
+	// each block and character.
+	batch, err := sprite.NewBatch(screen)
 	if err != nil {
 		panic(err)
 	}
+	defer batch.Release()
 
 	for _, s := range scene.Sprites {
 		s.Bind(screen.Program)
+		if c, ok := s.(*sprite.Context); ok {
+			c.Batch = batch
+		}
 	}
 
 	font, err := fonts.SimpleASCII()
@@ -96,98 +100,85 @@ func (c *Context) Main(screen *display.Context, config Config) {
 		panic(err)
 	}
 	font.Bind(screen.Program)
+	font.Sprite.Batch = batch
 
-	for running := true; running; {
-
-		screen.Fill(0, 0, 0)
-
-		dt := clock.Tick(30)
-
-		// TODO move this somewhere else (maybe a Clear method of display
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		for _, event := range events.Get() {
-			if event.Type == events.KeyUp && event.Key == glfw.KeyEscape {
-				// Send window close event
-				screen.Close()
-			}
-			if event.Type == events.WindowClose {
-				// Handle window close
-				running = false
-			}
-			for _, e := range scene.Objects {
-				if h, ok := e.(events.Handler); ok {
-					h.Handle(event)
-				}
-			}
-		}
+	g := play{
+		screen: screen,
+		config: config,
+		cam:    cam,
+		scene:  scene,
+		font:   font,
+	}
+	// The player's gravity is applied each update, and tuned for 30 a second.
+	shade.RunOptions(screen, &g, shade.Options{Timestep: 1.0 / 30.0})
+}
 
-		//cam.Move(scene.Player.Pos())
-		cam.Follow(scene.Player.Pos(), 0.1)
+// play is the game being run by shade.Run.
+type play struct {
+	screen *display.Context
+	config Config
+	cam    *camera.Context
+	scene  *Scene
+	font   *fonts.Context
+}
 
-		for _, e := range scene.Objects {
-			if u, ok := e.(entity.Updater); ok {
-				u.Update(dt/1000, &scene.Objects)
-			}
-			if d, ok := e.(entity.Drawer); ok {
-				d.Draw()
-			}
+// HandleEvent closes the window on escape and passes events to the scene's objects.
+func (g *play) HandleEvent(event events.Event) {
+	if event.Type == events.KeyUp && event.Key == glfw.KeyEscape {
+		// Send window close event
+		g.screen.Close()
+	}
+	for _, e := range g.scene.Objects.Entities() {
+		if h, ok := e.(events.Handler); ok {
+			h.Handle(event)
 		}
+	}
+}
 
-		//scene.Player.Update(dt/1000.0, scene.Walls)
-
-		/*
-			effect := sprite.Effects{
-				Scale:          mgl32.Vec3{1.0, 1.0, 1.0},
-				EnableLighting: true,
-				AmbientColor:   mgl32.Vec4{0.3, 0.3, 0.3, 1.0},
-				Light:          *scene.Player.Light}
-		*/
+// Update the scene's objects and follow the player with the camera.
+func (g *play) Update(dt float32) {
+	g.scene.Objects.Update(dt)
+	g.cam.Follow(g.scene.Player.Pos(), 0.1)
+}
 
-		/*
-			for _, w := range scene.Walls {
-				e, ok := w.(entity.Entity)
-				if ok {
-					println("OKDRAW")
-					e.Draw()
-				}
-			}
-			scene.Player.Draw()
-		*/
+// Draw the scene's objects, and the dev mode text if enabled.
+func (g *play) Draw(alpha float32) {
+	g.scene.Objects.Draw()
 
-		if config.DevMode {
-			deveff := sprite.Effects{
-				EnableLighting: false,
-				Scale:          mgl32.Vec3{2.0, 2.0, 1.0},
-				Tint:           mgl32.Vec4{1.0, 1.0, 1.0, 1.0},
-			}
-			msg := "Dev Mode!\n"
-			msg += fmt.Sprintf("Camera Pos: %.0f, %.0f\n", cam.Pos[0], cam.Pos[1])
-			msg += fmt.Sprintf("Player {\n")
-			msg += fmt.Sprintf("  Pos: %v\n", scene.Player.Pos())
-			msg += fmt.Sprintf("  Facing: %.0f\n", scene.Player.Facing)
-			msg += fmt.Sprintf("  Resting: %t\n", scene.Player.Resting)
-			msg += fmt.Sprintf("  Walking: %t\n", scene.Player.Walking)
-			msg += fmt.Sprintf("  Light: {\n")
-			msg += fmt.Sprintf("    Pos: %.0f, %.0f\n", scene.Player.Light.Pos[0], scene.Player.Light.Pos[1])
-			msg += fmt.Sprintf("  }\n")
-			msg += fmt.Sprintf("}\n")
-			font.DrawText(mgl32.Vec3{cam.Left + 20, cam.Top - 40, 0}, &deveff, msg)
+	if g.config.DevMode {
+		cam, scene := g.cam, g.scene
+		deveff := sprite.Effects{
+			EnableLighting: false,
+			Scale:          mgl32.Vec3{2.0, 2.0, 1.0},
+			Tint:           mgl32.Vec4{1.0, 1.0, 1.0, 1.0},
 		}
-		screen.Flip()
-		events.Poll()
-
+		msg := "Dev Mode!\n"
+		msg += fmt.Sprintf("Camera Pos: %.0f, %.0f\n", cam.Pos[0], cam.Pos[1])
+		msg += fmt.Sprintf("Player {\n")
+		msg += fmt.Sprintf("  Pos: %v\n", scene.Player.Pos())
+		msg += fmt.Sprintf("  Facing: %.0f\n", scene.Player.Facing)
+		msg += fmt.Sprintf("  Resting: %t\n", scene.Player.Resting)
+		msg += fmt.Sprintf("  Walking: %t\n", scene.Player.Walking)
+		msg += fmt.Sprintf("  Light: {\n")
+		msg += fmt.Sprintf("    Pos: %.0f, %.0f\n", scene.Player.Light.Pos[0], scene.Player.Light.Pos[1])
+		msg += fmt.Sprintf("  }\n")
+		msg += fmt.Sprintf("}\n")
+		g.font.DrawText(mgl32.Vec3{cam.Left + 20, cam.Top - 40, 2}, &deveff, msg)
 	}
 }
 
 // sprites, player, collidable
 func loadMap(path string) (*Scene, error) {
-	scene := Scene{}
+	scene := Scene{Objects: sprite.NewGroup()}
 
 	playerSprite, err := loadSpriteAsset("assets/gopher128x128.png", "assets/gopher128x128.normal.png", 3, 2)
 	if err != nil {
 		return &scene, err
 	}
+	// The gopher's shadow map gives it depth, such as the hat's brim shading its face.
+	if playerSprite.ShadowMap, err = sprite.LoadAsset("assets/gopher128x128.shadow.png"); err != nil {
+		return &scene, err
+	}
 	scene.Sprites = append(scene.Sprites, playerSprite)
 	blockSprite, err := loadSpriteAsset("assets/block64x64.png", "assets/block64x64.normal.png", 1, 1)
 	if err != nil {
@@ -217,10 +208,10 @@ func loadMap(path string) (*Scene, error) {
 		for _, c := range lines[i] {
 			switch c {
 			case '#':
-				scene.Objects = append(scene.Objects, block.New(float32(x), float32(y), blockSprite))
+				scene.Objects.Add(block.New(float32(x), float32(y), blockSprite))
 			case 'S':
 				scene.Player = player.New(x, y, playerSprite)
-				scene.Objects = append(scene.Objects, scene.Player)
+				scene.Objects.Add(scene.Player)
 			}
 			x += float32(blockSprite.Width)
 		}
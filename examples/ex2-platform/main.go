@@ -44,6 +44,7 @@ func main() {
 	if err != nil {
 		log.Fatalln("failed to set display mode:", err)
 	}
+	defer screen.Terminate()
 
 	g, err := game.New(screen)
 	if err != nil {
@@ -19,9 +19,8 @@ import (
 	"math"
 	"runtime"
 
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl32"
-	"github.com/hurricanerix/shade/entity"
 	"github.com/hurricanerix/shade/events"
 	"github.com/hurricanerix/shade/light"
 	"github.com/hurricanerix/shade/shapes"
@@ -46,7 +45,7 @@ type Player struct {
 	leftKey  bool
 	rightKey bool
 	jumpKey  bool
-	whichLeg int
+	anim     *sprite.Controller
 }
 
 // New TODO doc
@@ -57,6 +56,7 @@ func New(x, y float32, s *sprite.Context) *Player {
 		Shape:  shapes.NewRect(32, 96, 0, 96),
 		Sprite: s,
 		Facing: 2,
+		anim:   newController(),
 	}
 	light := light.Positional{
 		Pos:   mgl32.Vec3{p.pos[0], float32(s.Height), 50.0},
@@ -67,6 +67,34 @@ func New(x, y float32, s *sprite.Context) *Player {
 	return &p
 }
 
+// newController of the player's animations.  Frames are columns of the sprite,
+// the row depends on which way the player is facing.
+func newController() *sprite.Controller {
+	c := sprite.NewController()
+	stand := sprite.NewAnimation("stand", sprite.Loop, 100, mgl32.Vec2{0, 0})
+	idle := c.AddState("idle", stand)
+	walk := c.AddState("walk", sprite.NewAnimation("walk", sprite.Loop, 80, mgl32.Vec2{1, 0}, mgl32.Vec2{2, 0}))
+	jump := c.AddState("jump", stand)
+	fall := c.AddState("fall", stand)
+
+	for _, s := range []*sprite.State{idle, walk} {
+		s.To("jump", sprite.IfNot("Resting"), sprite.IfGreater("dy", 0))
+		s.To("fall", sprite.IfNot("Resting"), sprite.IfLess("dy", 0))
+	}
+	idle.To("walk", sprite.If("Walking"), sprite.If("Resting"))
+	walk.To("idle", sprite.IfNot("Walking"))
+	// Walking off a ledge, before gravity has given the player any speed.
+	walk.To("fall", sprite.IfNot("Resting"))
+	jump.To("fall", sprite.IfLess("dy", 0))
+	jump.To("idle", sprite.If("Resting"))
+	fall.To("idle", sprite.If("Resting"))
+
+	if err := c.Start("idle"); err != nil {
+		panic(err)
+	}
+	return c
+}
+
 func (p Player) Bounds() shapes.Shape {
 	return *p.Shape
 }
@@ -105,7 +133,7 @@ func (p *Player) Bind(program uint32) error {
 }
 
 // Update TODO doc
-func (p *Player) Update(dt float32, group *[]entity.Entity) {
+func (p *Player) Update(dt float32, group *sprite.Group) {
 	lastPos := mgl32.Vec3{p.pos[0], p.pos[1], p.pos[2]}
 	p.Walking = false
 
@@ -137,14 +165,7 @@ func (p *Player) Update(dt float32, group *[]entity.Entity) {
 		p.dy = 0.0
 	}
 
-	var cgroup []entity.Collider
-	for i := range *group {
-		if c, ok := (*group)[i].(entity.Collider); ok {
-			cgroup = append(cgroup, c)
-		}
-	}
-	collides := entity.Collide(p, &cgroup, false)
-	for _, c := range collides {
+	for _, c := range group.Collide(p, false) {
 		pos := c.Hit.Pos()
 		s := c.Hit.Bounds().Data
 
@@ -166,22 +187,15 @@ func (p *Player) Update(dt float32, group *[]entity.Entity) {
 		}
 	}
 	p.Light.Pos[1] = p.pos[1] + float32(p.Sprite.Height)
+
+	p.anim.SetBool("Walking", p.Walking)
+	p.anim.SetBool("Resting", p.Resting)
+	p.anim.SetFloat("dy", p.dy)
+	p.anim.Update(dt)
 }
 
 // Draw TODO doc
 func (p *Player) Draw() {
-	if !p.Walking || !p.Resting {
-		p.Sprite.DrawFrame(mgl32.Vec2{0, p.Facing}, p.pos, nil)
-	} else {
-		frame := float32(int(p.dy) % 2)
-		switch {
-		case p.whichLeg == 0:
-			p.whichLeg = 1
-		case p.whichLeg == 1:
-			p.whichLeg = 2
-		case p.whichLeg == 2:
-			p.whichLeg = 1
-		}
-		p.Sprite.DrawFrame(mgl32.Vec2{frame + float32(p.whichLeg), p.Facing}, p.pos, nil)
-	}
+	frame := p.anim.Frame()
+	p.Sprite.DrawFrame(mgl32.Vec2{frame[0], p.Facing}, p.pos, nil)
 }
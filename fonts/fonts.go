@@ -79,6 +79,11 @@ func (c *Context) Bind(program uint32) {
 	c.Sprite.Bind(program)
 }
 
+// Release the font's sprite, see sprite.Context.Release.
+func (c *Context) Release() {
+	c.Sprite.Release()
+}
+
 // DrawText TODO doc
 //func (c Context) DrawText(x, y, sx, sy float32, color *mgl32.Vec4, msg string) {
 func (c *Context) DrawText(pos mgl32.Vec3, e *sprite.Effects, msg string) {
@@ -0,0 +1,118 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gpu keeps track of the OpenGL objects created by shade, so objects which
+// are never released can be found when the game shuts down.
+package gpu
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+)
+
+// Debug enables tracking.  It must be set before any objects are created, usually
+// with display.Options.Debug, and costs a stack trace for every object created.
+var Debug bool
+
+// Kinds of OpenGL objects which are tracked.
+const (
+	Texture      = "texture"
+	VertexArray  = "vertex array"
+	Buffer       = "buffer"
+	Framebuffer  = "framebuffer"
+	Renderbuffer = "renderbuffer"
+	Program      = "program"
+)
+
+// Object is a live OpenGL object.
+type Object struct {
+	Kind string
+	ID   uint32
+	// Stack of function calls which created the object, innermost first.
+	Stack []string
+}
+
+type key struct {
+	kind string
+	id   uint32
+}
+
+// live objects by kind and ID.
+var live = map[key]Object{}
+
+// How many calls of the stack are kept for each object.
+const stackDepth = 8
+
+// Created records that the OpenGL object id of kind was created.
+func Created(kind string, id uint32) {
+	if !Debug || id == 0 {
+		return
+	}
+	var stack []string
+	// Skip Created.
+	for i := 1; i <= stackDepth; i++ {
+		pc, file, line, ok := runtime.Caller(i)
+		if !ok {
+			break
+		}
+		stack = append(stack, fmt.Sprintf("%s (%s:%d)", runtime.FuncForPC(pc).Name(), file, line))
+	}
+	live[key{kind, id}] = Object{
+		Kind:  kind,
+		ID:    id,
+		Stack: stack,
+	}
+}
+
+// Deleted records that the OpenGL object id of kind was deleted.
+func Deleted(kind string, id uint32) {
+	delete(live, key{kind, id})
+}
+
+// Live returns the objects which have been created but not deleted, ordered by kind
+// then ID.
+func Live() []Object {
+	objects := make([]Object, 0, len(live))
+	for _, o := range live {
+		objects = append(objects, o)
+	}
+	sort.Sort(byKind(objects))
+	return objects
+}
+
+type byKind []Object
+
+func (a byKind) Len() int      { return len(a) }
+func (a byKind) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byKind) Less(i, j int) bool {
+	if a[i].Kind != a[j].Kind {
+		return a[i].Kind < a[j].Kind
+	}
+	return a[i].ID < a[j].ID
+}
+
+// Report writes each live object and where it was created to w, returning how many
+// there are.
+func Report(w io.Writer) int {
+	objects := Live()
+	for _, o := range objects {
+		fmt.Fprintf(w, "Leaked %s %d, created by:\n", o.Kind, o.ID)
+		for _, call := range o.Stack {
+			fmt.Fprintf(w, "\t%s\n", call)
+		}
+	}
+	return len(objects)
+}
@@ -0,0 +1,58 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gpu
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLive(t *testing.T) {
+	Debug = true
+	defer func() {
+		Debug = false
+		live = map[key]Object{}
+	}()
+
+	Created(Texture, 2)
+	Created(Buffer, 1)
+	Created(Texture, 1)
+	Created(Program, 0)
+	Deleted(Texture, 2)
+
+	objects := Live()
+	if len(objects) != 2 {
+		t.Fatalf("Expected 2 live objects but found %d", len(objects))
+	}
+	if objects[0].Kind != Buffer || objects[1].Kind != Texture || objects[1].ID != 1 {
+		t.Error("Expected live buffer 1 and texture 1 but found", objects)
+	}
+
+	var buf bytes.Buffer
+	if n := Report(&buf); n != 2 {
+		t.Error("Expected Report to return 2 but found", n)
+	}
+	if !strings.Contains(buf.String(), "TestLive") {
+		t.Error("Expected report to include the creating function but found", buf.String())
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	Created(Texture, 1)
+	if objects := Live(); len(objects) != 0 {
+		t.Error("Expected nothing to be tracked without Debug but found", objects)
+	}
+}
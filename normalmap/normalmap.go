@@ -0,0 +1,140 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package normalmap generates tangent-space normal maps for sprites, from a
+// grayscale height map or from the luminance of a color map.
+//
+// Height is taken from each pixel's luminance multiplied by its alpha, so the
+// edges of a sprite's transparent areas are beveled.  Normals are encoded like the
+// hand painted *.normal.png files in the assets directory, with red pointing right
+// and green pointing up.
+package normalmap
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// DefaultStrength of the generated normals, used when Options.Strength is 0.
+const DefaultStrength = 2.0
+
+// Options for Generate.
+type Options struct {
+	// Strength the slopes of the height map are exaggerated by.  Higher values
+	// give more pronounced bumps.
+	Strength float32
+	// Blur radius in pixels, applied to the height map to soften noisy art.
+	Blur int
+	// Invert the height map, so darker pixels are higher.
+	Invert bool
+}
+
+// Generate a normal map the size of src, from its heights filtered with a Sobel
+// operator.
+func Generate(src image.Image, opts Options) *image.RGBA {
+	strength := opts.Strength
+	if strength == 0 {
+		strength = DefaultStrength
+	}
+	h := heights(src, opts.Invert)
+	if opts.Blur > 0 {
+		h = h.blur(opts.Blur)
+	}
+
+	b := src.Bounds()
+	dst := image.NewRGBA(b)
+	for y := 0; y < h.height; y++ {
+		for x := 0; x < h.width; x++ {
+			// Sobel, with y increasing down the image.
+			dx := (h.at(x+1, y-1) + 2*h.at(x+1, y) + h.at(x+1, y+1)) -
+				(h.at(x-1, y-1) + 2*h.at(x-1, y) + h.at(x-1, y+1))
+			dy := (h.at(x-1, y+1) + 2*h.at(x, y+1) + h.at(x+1, y+1)) -
+				(h.at(x-1, y-1) + 2*h.at(x, y-1) + h.at(x+1, y-1))
+			// Normals face away from the slope, up is towards the top of the image.
+			dst.SetRGBA(b.Min.X+x, b.Min.Y+y, encode(-dx*strength, dy*strength, 1))
+		}
+	}
+	return dst
+}
+
+// encode the normal (x, y, z) as a color.
+func encode(x, y, z float32) color.RGBA {
+	l := float32(math.Sqrt(float64(x*x + y*y + z*z)))
+	c := func(v float32) uint8 {
+		return uint8(math.Floor(float64((v/l*0.5+0.5)*255 + 0.5)))
+	}
+	return color.RGBA{c(x), c(y), c(z), 255}
+}
+
+// heightMap of values from 0 to 1.
+type heightMap struct {
+	width  int
+	height int
+	values []float32
+}
+
+func heights(src image.Image, invert bool) heightMap {
+	b := src.Bounds()
+	h := heightMap{
+		width:  b.Dx(),
+		height: b.Dy(),
+		values: make([]float32, b.Dx()*b.Dy()),
+	}
+	for y := 0; y < h.height; y++ {
+		for x := 0; x < h.width; x++ {
+			// RGBA is premultiplied, so transparent pixels are low.
+			r, g, bl, a := src.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			v := (0.299*float32(r) + 0.587*float32(g) + 0.114*float32(bl)) / 0xffff
+			if invert {
+				v = float32(a)/0xffff - v
+			}
+			h.values[y*h.width+x] = v
+		}
+	}
+	return h
+}
+
+// at returns the height at x, y, clamped to the edges of the map.
+func (h heightMap) at(x, y int) float32 {
+	if x < 0 {
+		x = 0
+	} else if x >= h.width {
+		x = h.width - 1
+	}
+	if y < 0 {
+		y = 0
+	} else if y >= h.height {
+		y = h.height - 1
+	}
+	return h.values[y*h.width+x]
+}
+
+// blur the map with a box filter of radius r, horizontally then vertically.
+func (h heightMap) blur(r int) heightMap {
+	pass := func(src heightMap, dx, dy int) heightMap {
+		dst := heightMap{width: src.width, height: src.height, values: make([]float32, len(src.values))}
+		for y := 0; y < src.height; y++ {
+			for x := 0; x < src.width; x++ {
+				var sum float32
+				for i := -r; i <= r; i++ {
+					sum += src.at(x+i*dx, y+i*dy)
+				}
+				dst.values[y*dst.width+x] = sum / float32(2*r+1)
+			}
+		}
+		return dst
+	}
+	return pass(pass(h, 1, 0), 0, 1)
+}
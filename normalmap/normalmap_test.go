@@ -0,0 +1,113 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalmap
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// ramp of heights increasing to the right, or down if vertical.
+func ramp(vertical bool) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			v := x
+			if vertical {
+				v = y
+			}
+			img.SetGray(x, y, color.Gray{uint8(v * 32)})
+		}
+	}
+	return img
+}
+
+func TestGenerateFlat(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 4, 4))
+	n := Generate(img, Options{})
+	want := color.RGBA{128, 128, 255, 255}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if got := n.RGBAAt(x, y); got != want {
+				t.Fatalf("normal at %d,%d was %v, expected %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+// sign of an encoded normal component, -1 if it points left or down.
+func sign(v uint8) int {
+	switch {
+	case v < 128:
+		return -1
+	case v > 128:
+		return 1
+	}
+	return 0
+}
+
+func TestGenerateSlopes(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+		opts Options
+		x, y int
+	}{
+		// Rising to the right faces left.
+		{"right", ramp(false), Options{}, -1, 0},
+		{"right inverted", ramp(false), Options{Invert: true}, 1, 0},
+		// Rising towards the bottom of the image faces up.
+		{"down", ramp(true), Options{}, 0, 1},
+	}
+	for _, tt := range tests {
+		n := Generate(tt.img, tt.opts).RGBAAt(4, 4)
+		if sign(n.R) != tt.x || sign(n.G) != tt.y || n.B <= 128 {
+			t.Errorf("%s: normal was %v, expected x %d and y %d facing out of the screen", tt.name, n, tt.x, tt.y)
+		}
+	}
+}
+
+func TestGenerateStrengthAndBlur(t *testing.T) {
+	img := ramp(false)
+	weak := Generate(img, Options{Strength: 1}).RGBAAt(4, 4)
+	strong := Generate(img, Options{Strength: 4}).RGBAAt(4, 4)
+	if !(strong.R < weak.R) {
+		t.Errorf("stronger normal %v was not steeper than %v", strong, weak)
+	}
+
+	// A single raised pixel is spread out by blurring.
+	dot := image.NewGray(image.Rect(0, 0, 9, 9))
+	dot.SetGray(4, 4, color.Gray{255})
+	sharp := Generate(dot, Options{}).RGBAAt(1, 4)
+	blurred := Generate(dot, Options{Blur: 2}).RGBAAt(1, 4)
+	if sharp.R != 128 || blurred.R == 128 {
+		t.Errorf("normal beside the dot was %v sharp and %v blurred", sharp, blurred)
+	}
+}
+
+func TestGenerateTransparentEdges(t *testing.T) {
+	// An opaque white square on a transparent background is beveled.
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 2; y < 6; y++ {
+		for x := 2; x < 6; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{255, 255, 255, 255})
+		}
+	}
+	n := Generate(img, Options{})
+	if l, r := n.RGBAAt(2, 4), n.RGBAAt(5, 4); !(l.R < 128 && r.R > 128) {
+		t.Errorf("edges were %v and %v, expected facing left and right", l, r)
+	}
+}
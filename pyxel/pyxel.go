@@ -0,0 +1,239 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pyxel loads Pyxel Edit documents, so sprites can be made straight from
+// the .pyxel files in the assets directory instead of PNGs exported by hand.
+package pyxel
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade/gen"
+	"github.com/hurricanerix/shade/sprite"
+)
+
+// Document of Pyxel Edit, a canvas of tiles in one or more layers.
+type Document struct {
+	// Width and Height of the canvas in pixels.
+	Width  int
+	Height int
+	// TileWidth and TileHeight in pixels, each tile is one frame of the sprite.
+	TileWidth  int
+	TileHeight int
+	// Layers from top to bottom.
+	Layers []Layer
+	// Image of the visible layers composited together.
+	Image image.Image
+	// Animations by name.  Their frames are tiles of the canvas, see Frame.
+	Animations map[string]*sprite.Animation
+}
+
+// Layer of a Document.
+type Layer struct {
+	Name   string
+	Hidden bool
+	// Alpha the layer is composited with, from 0 to 255.
+	Alpha int
+	Image image.Image
+}
+
+type docData struct {
+	Canvas struct {
+		Width      int
+		Height     int
+		TileWidth  int
+		TileHeight int
+		NumLayers  int
+		Layers     map[string]struct {
+			Name   string
+			Hidden bool
+			Alpha  int
+		}
+	}
+	Animations map[string]struct {
+		Name                     string
+		BaseTile                 int
+		Length                   int
+		FrameDuration            float32
+		FrameDurationMultipliers []float32
+	}
+}
+
+// Decode the .pyxel zip archive in r, which is size bytes long.  Layers are
+// composited with their alpha, other blend modes are drawn as normal.
+func Decode(r io.ReaderAt, size int64) (*Document, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("could not read archive: %v", err)
+	}
+	files := make(map[string]*zip.File, len(z.File))
+	for _, f := range z.File {
+		files[f.Name] = f
+	}
+
+	var data docData
+	if err := decodeFile(files, "docData.json", func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&data)
+	}); err != nil {
+		return nil, err
+	}
+	canvas := data.Canvas
+	if canvas.Width <= 0 || canvas.Height <= 0 || canvas.TileWidth <= 0 || canvas.TileHeight <= 0 {
+		return nil, fmt.Errorf("invalid canvas %dx%d with %dx%d tiles", canvas.Width, canvas.Height, canvas.TileWidth, canvas.TileHeight)
+	}
+
+	d := Document{
+		Width:      canvas.Width,
+		Height:     canvas.Height,
+		TileWidth:  canvas.TileWidth,
+		TileHeight: canvas.TileHeight,
+		Animations: make(map[string]*sprite.Animation, len(data.Animations)),
+	}
+	for i := 0; i < canvas.NumLayers; i++ {
+		l, ok := canvas.Layers[strconv.Itoa(i)]
+		if !ok {
+			return nil, fmt.Errorf("could not find layer %d", i)
+		}
+		layer := Layer{
+			Name:   l.Name,
+			Hidden: l.Hidden,
+			Alpha:  l.Alpha,
+		}
+		if err := decodeFile(files, fmt.Sprintf("layer%d.png", i), func(r io.Reader) error {
+			layer.Image, err = png.Decode(r)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		d.Layers = append(d.Layers, layer)
+	}
+	d.Image = d.composite()
+
+	cols := d.FramesX()
+	for _, a := range data.Animations {
+		if a.BaseTile < 0 || a.Length < 1 || a.BaseTile+a.Length > cols*d.FramesY() {
+			return nil, fmt.Errorf("could not decode animation %s: tiles %d to %d out of range", a.Name, a.BaseTile, a.BaseTile+a.Length-1)
+		}
+		anim := sprite.Animation{Name: a.Name}
+		for i := 0; i < a.Length; i++ {
+			// Multipliers are percentages of the animation's frame duration.
+			duration := a.FrameDuration
+			if i < len(a.FrameDurationMultipliers) {
+				duration = duration * a.FrameDurationMultipliers[i] / 100
+			}
+			anim.Frames = append(anim.Frames, sprite.AnimationFrame{
+				Frame:    d.Frame(a.BaseTile + i),
+				Duration: duration,
+			})
+		}
+		d.Animations[a.Name] = &anim
+	}
+	return &d, nil
+}
+
+// decodeFile called name in the archive with decode.
+func decodeFile(files map[string]*zip.File, name string, decode func(io.Reader) error) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("could not find %s in archive", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("could not open %s: %v", name, err)
+	}
+	defer r.Close()
+	if err := decode(r); err != nil {
+		return fmt.Errorf("could not decode %s: %v", name, err)
+	}
+	return nil
+}
+
+// composite the visible layers, bottom to top, into the size of the canvas.
+func (d *Document) composite() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, d.Width, d.Height))
+	for i := len(d.Layers) - 1; i >= 0; i-- {
+		l := d.Layers[i]
+		if l.Hidden || l.Alpha <= 0 {
+			continue
+		}
+		mask := image.NewUniform(color.Alpha{uint8(l.Alpha)})
+		draw.DrawMask(img, img.Bounds(), l.Image, l.Image.Bounds().Min, mask, image.Point{}, draw.Over)
+	}
+	return img
+}
+
+// Load the .pyxel document at path.
+func Load(path string) (*Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file %s: %v", path, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("could not stat file %s: %v", path, err)
+	}
+	d, err := Decode(f, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("could not load %s: %v", path, err)
+	}
+	return d, nil
+}
+
+// LoadAsset loads a .pyxel document like Load, from the assets compiled in by
+// bindata.sh.
+func LoadAsset(name string) (*Document, error) {
+	data, err := gen.Asset(name)
+	if err != nil {
+		return nil, fmt.Errorf("could not load asset %s: %v", name, err)
+	}
+	d, err := Decode(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("could not load %s: %v", name, err)
+	}
+	return d, nil
+}
+
+// FramesX is the number of tiles across the canvas.
+func (d *Document) FramesX() int {
+	return d.Width / d.TileWidth
+}
+
+// FramesY is the number of tiles down the canvas.
+func (d *Document) FramesY() int {
+	return d.Height / d.TileHeight
+}
+
+// Frame of the sprite for tile index, counting left to right, top to bottom.
+func (d *Document) Frame(index int) mgl32.Vec2 {
+	cols := d.FramesX()
+	return mgl32.Vec2{float32(index % cols), float32(index / cols)}
+}
+
+// Sprite of the document's image split into its tiles, with normalMap which may be
+// nil.  It is the same as passing Image, FramesX and FramesY to sprite.New.
+func (d *Document) Sprite(normalMap image.Image) (*sprite.Context, error) {
+	return sprite.New(d.Image, normalMap, d.FramesX(), d.FramesY())
+}
@@ -0,0 +1,170 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyxel
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade/sprite"
+)
+
+const testDocData = `{
+  "animations": {
+    "0": {
+      "frameDurationMultipliers": [100, 150],
+      "baseTile": 1,
+      "length": 2,
+      "frameDuration": 100,
+      "name": "walk"
+    }
+  },
+  "canvas": {
+    "width": 4,
+    "height": 4,
+    "tileWidth": 2,
+    "tileHeight": 2,
+    "numLayers": 3,
+    "layers": {
+      "0": { "name": "top", "hidden": false, "alpha": 255, "blendMode": "normal" },
+      "1": { "name": "hidden", "hidden": true, "alpha": 255, "blendMode": "normal" },
+      "2": { "name": "bottom", "hidden": false, "alpha": 255, "blendMode": "normal" }
+    }
+  },
+  "version": "0.3.108"
+}`
+
+// fill a 4x4 layer with c inside r, leaving the rest transparent.
+func fill(r image.Rectangle, c color.Color) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func archive(t *testing.T, layers ...image.Image) []byte {
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	w, err := z.Create("docData.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(testDocData))
+	for i, l := range layers {
+		w, err := z.Create(fmt.Sprintf("layer%d.png", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := png.Encode(w, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	green := color.NRGBA{0, 255, 0, 255}
+	blue := color.NRGBA{0, 0, 255, 255}
+	data := archive(t,
+		fill(image.Rect(0, 0, 2, 2), red),
+		fill(image.Rect(0, 0, 4, 4), green),
+		fill(image.Rect(0, 0, 4, 2), blue))
+
+	d, err := Decode(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.TileWidth != 2 || d.TileHeight != 2 || d.FramesX() != 2 || d.FramesY() != 2 {
+		t.Error("Expected 2x2 tiles in 2x2 frames but found", d.TileWidth, d.TileHeight, d.FramesX(), d.FramesY())
+	}
+	if len(d.Layers) != 3 || d.Layers[0].Name != "top" || !d.Layers[1].Hidden {
+		t.Error("Expected layers top, a hidden layer and another but found", d.Layers)
+	}
+
+	tests := []struct {
+		p    image.Point
+		want color.Color
+	}{
+		{image.Pt(0, 0), red},
+		{image.Pt(3, 1), blue},
+		{image.Pt(1, 3), color.NRGBA{}},
+	}
+	for _, tt := range tests {
+		r, g, b, a := d.Image.At(tt.p.X, tt.p.Y).RGBA()
+		wr, wg, wb, wa := tt.want.RGBA()
+		if r != wr || g != wg || b != wb || a != wa {
+			t.Error("Expected pixel", tt.p, "to be", tt.want, "but found", d.Image.At(tt.p.X, tt.p.Y))
+		}
+	}
+
+	walk, ok := d.Animations["walk"]
+	if !ok {
+		t.Fatal("Expected a walk animation but found none")
+	}
+	want := []sprite.AnimationFrame{
+		{Frame: mgl32.Vec2{1, 0}, Duration: 100},
+		{Frame: mgl32.Vec2{0, 1}, Duration: 150},
+	}
+	if len(walk.Frames) != len(want) {
+		t.Fatal("Expected", len(want), "walk frames but found", len(walk.Frames))
+	}
+	for i := range want {
+		if walk.Frames[i] != want[i] {
+			t.Error("Expected walk frame", i, "to be", want[i], "but found", walk.Frames[i])
+		}
+	}
+}
+
+func TestDecodeMissingLayer(t *testing.T) {
+	data := archive(t, fill(image.Rect(0, 0, 1, 1), color.Black))
+	if _, err := Decode(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Error("Expected an error for a missing layer but found nil")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	d, err := Load("../assets/gopher128x128.pyxel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.FramesX() != 3 || d.FramesY() != 2 {
+		t.Error("Expected 3x2 frames but found", d.FramesX(), d.FramesY())
+	}
+	if a := d.Animations["walk left"]; a == nil || len(a.Frames) != 2 || a.Frames[0].Frame != (mgl32.Vec2{1, 1}) {
+		t.Error("Expected a walk left animation of 2 frames starting at {1, 1} but found", a)
+	}
+
+	s, err := d.Sprite(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Width != 128 || s.Height != 128 {
+		t.Error("Expected a 128x128 sprite but found", s.Width, s.Height)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scene manages a stack of scenes, such as a splash screen, menu, level and
+// pause overlay, so a game can move between them.  A Manager is a shade.Game.
+package scene
+
+import (
+	"github.com/hurricanerix/shade"
+	"github.com/hurricanerix/shade/events"
+)
+
+// Scene is one screen of the game.  Only the scene on top of the stack is updated
+// and receives events, if it is a shade.EventHandler.
+type Scene interface {
+	Update(dt float32)
+	Draw(alpha float32)
+}
+
+// Enterer is implemented by scenes which set up when they are pushed.
+type Enterer interface {
+	Enter()
+}
+
+// Exiter is implemented by scenes which clean up when they are popped or replaced.
+type Exiter interface {
+	Exit()
+}
+
+// Pauser is implemented by scenes which need to know when a scene is pushed on top
+// of them.
+type Pauser interface {
+	Pause()
+}
+
+// Resumer is implemented by scenes which need to know when the scene on top of
+// them is popped.
+type Resumer interface {
+	Resume()
+}
+
+type entry struct {
+	scene Scene
+	// overlay scenes are drawn over the scene below them
+	overlay bool
+}
+
+// Manager of the scene stack.
+type Manager struct {
+	stack []entry
+}
+
+// New returns a Manager with no scenes.
+func New() *Manager {
+	return &Manager{}
+}
+
+// Push s on top of the stack, pausing the current scene.
+func (m *Manager) Push(s Scene) {
+	m.push(entry{scene: s})
+}
+
+// PushOverlay pushes s on top of the stack like Push, but the scenes below it keep
+// being drawn, without being updated, before s is drawn over them.
+func (m *Manager) PushOverlay(s Scene) {
+	m.push(entry{scene: s, overlay: true})
+}
+
+func (m *Manager) push(e entry) {
+	if p, ok := m.Top().(Pauser); ok {
+		p.Pause()
+	}
+	m.stack = append(m.stack, e)
+	if e, ok := e.scene.(Enterer); ok {
+		e.Enter()
+	}
+}
+
+// Pop the scene on top of the stack, resuming the one below it.  Once the last
+// scene is popped the Manager is done.
+func (m *Manager) Pop() {
+	if len(m.stack) == 0 {
+		return
+	}
+	top := m.stack[len(m.stack)-1].scene
+	m.stack = m.stack[:len(m.stack)-1]
+	if e, ok := top.(Exiter); ok {
+		e.Exit()
+	}
+	if r, ok := m.Top().(Resumer); ok {
+		r.Resume()
+	}
+}
+
+// Replace the scene on top of the stack with s, without resuming the one below.
+func (m *Manager) Replace(s Scene) {
+	if len(m.stack) == 0 {
+		m.Push(s)
+		return
+	}
+	i := len(m.stack) - 1
+	top := m.stack[i].scene
+	m.stack[i] = entry{scene: s}
+	if e, ok := top.(Exiter); ok {
+		e.Exit()
+	}
+	if e, ok := s.(Enterer); ok {
+		e.Enter()
+	}
+}
+
+// Top returns the scene on top of the stack, or nil if there are none.
+func (m *Manager) Top() Scene {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1].scene
+}
+
+// Len returns how many scenes are on the stack.
+func (m *Manager) Len() int {
+	return len(m.stack)
+}
+
+// Done returns true once every scene has been popped.
+func (m *Manager) Done() bool {
+	return len(m.stack) == 0
+}
+
+// HandleEvent passes event to the scene on top of the stack.
+func (m *Manager) HandleEvent(event events.Event) {
+	if h, ok := m.Top().(shade.EventHandler); ok {
+		h.HandleEvent(event)
+	}
+}
+
+// Update the scene on top of the stack.
+func (m *Manager) Update(dt float32) {
+	if s := m.Top(); s != nil {
+		s.Update(dt)
+	}
+}
+
+// Draw the scene on top of the stack, after the scenes below it if it is an
+// overlay.
+func (m *Manager) Draw(alpha float32) {
+	if len(m.stack) == 0 {
+		return
+	}
+	first := len(m.stack) - 1
+	for first > 0 && m.stack[first].overlay {
+		first--
+	}
+	// Scenes may push or pop while drawing, so draw a copy of the stack.
+	for _, e := range append([]entry(nil), m.stack[first:]...) {
+		e.scene.Draw(alpha)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scene
+
+import (
+	"fmt"
+	"testing"
+)
+
+type logScene struct {
+	name string
+	log  *[]string
+}
+
+func (s logScene) Update(dt float32)  { *s.log = append(*s.log, s.name+".Update") }
+func (s logScene) Draw(alpha float32) { *s.log = append(*s.log, s.name+".Draw") }
+func (s logScene) Enter()             { *s.log = append(*s.log, s.name+".Enter") }
+func (s logScene) Exit()              { *s.log = append(*s.log, s.name+".Exit") }
+func (s logScene) Pause()             { *s.log = append(*s.log, s.name+".Pause") }
+func (s logScene) Resume()            { *s.log = append(*s.log, s.name+".Resume") }
+
+func TestManager(t *testing.T) {
+	var log []string
+	m := New()
+	level := logScene{"level", &log}
+	pause := logScene{"pause", &log}
+	menu := logScene{"menu", &log}
+
+	m.Push(level)
+	m.PushOverlay(pause)
+	m.Update(0.1)
+	m.Draw(0.0)
+	m.Pop()
+	m.Replace(menu)
+	m.Draw(0.0)
+	m.Pop()
+
+	expected := []string{
+		"level.Enter",
+		"level.Pause", "pause.Enter",
+		"pause.Update",
+		"level.Draw", "pause.Draw",
+		"pause.Exit", "level.Resume",
+		"level.Exit", "menu.Enter",
+		"menu.Draw",
+		"menu.Exit",
+	}
+	if fmt.Sprint(log) != fmt.Sprint(expected) {
+		t.Error("Expected calls", expected, "but found", log)
+	}
+	if !m.Done() {
+		t.Error("Expected manager to be done after popping every scene")
+	}
+}
+
+func TestDrawOnlyBelowOverlays(t *testing.T) {
+	var log []string
+	m := New()
+	m.Push(logScene{"menu", &log})
+	m.Push(logScene{"level", &log})
+	m.PushOverlay(logScene{"hud", &log})
+	m.PushOverlay(logScene{"pause", &log})
+	log = nil
+
+	m.Draw(0.0)
+
+	expected := []string{"level.Draw", "hud.Draw", "pause.Draw"}
+	if fmt.Sprint(log) != fmt.Sprint(expected) {
+		t.Error("Expected calls", expected, "but found", log)
+	}
+}
+
+func TestEmptyManager(t *testing.T) {
+	var log []string
+	m := New()
+	m.Push(logScene{"level", &log})
+	m.Pop()
+	log = nil
+
+	m.Update(0.1)
+	m.Draw(0.0)
+
+	if len(log) != 0 {
+		t.Error("Expected no calls but found", log)
+	}
+}
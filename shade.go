@@ -0,0 +1,145 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shade runs games built with the other shade packages, see Run.
+package shade
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/hurricanerix/shade/display"
+	"github.com/hurricanerix/shade/events"
+)
+
+func init() {
+	// GLFW event handling must run on the main OS thread
+	runtime.LockOSThread()
+}
+
+// Game is run by Run.
+type Game interface {
+	// Update the simulation by dt seconds, which is always Options.Timestep.
+	Update(dt float32)
+	// Draw the game to the screen.  alpha is how far from 0 to 1 the frame is
+	// between the last Update and the next one, for interpolating positions so
+	// movement looks smooth when the frame rate does not match the timestep.
+	Draw(alpha float32)
+}
+
+// EventHandler is implemented by Games which handle events.
+type EventHandler interface {
+	// HandleEvent is called for each event before the frame's updates.
+	HandleEvent(event events.Event)
+}
+
+// Finisher is implemented by Games which can end without the window closing.
+type Finisher interface {
+	// Done returns true once the game is finished, which Run checks after Update.
+	Done() bool
+}
+
+// Options for running a game with RunOptions.
+type Options struct {
+	// Timestep of each Update in seconds, 1/60 if zero.
+	Timestep float32
+	// MaxFrameSkip is how many Updates may run for a single frame, 5 if zero.  If
+	// the game falls further behind than this, the simulation slows down rather than
+	// spending ever more time catching up.
+	MaxFrameSkip int
+	// Background color the screen is cleared to before Draw.
+	Background [3]float32
+}
+
+// Run game on screen until the window is closed or it is done, see RunOptions.
+func Run(screen *display.Context, game Game) {
+	RunOptions(screen, game, Options{})
+}
+
+// RunOptions runs game on screen until the window is closed or, if game is a
+// Finisher, it is done.  Each frame events are passed to the game if it is an
+// EventHandler, then Update is called as many times as needed to catch up to the
+// time passed, then the screen is cleared, Draw is called and the screen is
+// flipped.  Because Update always steps the same amount of time, the simulation
+// does not depend on the frame rate.
+//
+// To stop the game, call screen.Close.
+func RunOptions(screen *display.Context, game Game, opts Options) {
+	if opts.Timestep <= 0 {
+		opts.Timestep = 1.0 / 60.0
+	}
+	if opts.MaxFrameSkip <= 0 {
+		opts.MaxFrameSkip = 5
+	}
+	t := timestep{
+		step:    opts.Timestep,
+		maxStep: opts.MaxFrameSkip,
+	}
+	handler, _ := game.(EventHandler)
+	finisher, _ := game.(Finisher)
+
+	last := time.Now()
+	for running := true; running; {
+		now := time.Now()
+		steps, alpha := t.advance(float32(now.Sub(last).Seconds()))
+		last = now
+
+		for _, event := range events.Get() {
+			if event.Type == events.WindowClose {
+				running = false
+			}
+			if handler != nil {
+				handler.HandleEvent(event)
+			}
+		}
+
+		for i := 0; i < steps; i++ {
+			game.Update(t.step)
+		}
+		if finisher != nil && finisher.Done() {
+			return
+		}
+
+		screen.Fill(opts.Background[0], opts.Background[1], opts.Background[2])
+		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+		game.Draw(alpha)
+
+		screen.Flip()
+		events.Poll()
+	}
+}
+
+// timestep accumulates frame times into fixed size steps.
+type timestep struct {
+	step    float32
+	maxStep int
+	// acc is the time passed which has not been stepped yet
+	acc float32
+}
+
+// advance by a frame of seconds, returning how many steps to update and how far
+// the remaining time is into the next step.
+func (t *timestep) advance(seconds float32) (steps int, alpha float32) {
+	t.acc += seconds
+	for t.acc >= t.step && steps < t.maxStep {
+		t.acc -= t.step
+		steps++
+	}
+	if t.acc >= t.step {
+		// Too far behind, drop the time instead of catching up.
+		t.acc = 0
+	}
+	return steps, t.acc / t.step
+}
@@ -0,0 +1,44 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shade
+
+import "testing"
+
+func TestTimestepAdvance(t *testing.T) {
+	ts := timestep{step: 0.25, maxStep: 3}
+
+	data := []struct {
+		seconds float32
+		steps   int
+		alpha   float32
+	}{
+		{0.1, 0, 0.4},
+		{0.2, 1, 0.2},
+		{0.5, 2, 0.2},
+		// More than maxStep behind, the rest is dropped.
+		{2.0, 3, 0.0},
+		{0.25, 1, 0.0},
+	}
+
+	for i, d := range data {
+		steps, alpha := ts.advance(d.seconds)
+		if steps != d.steps {
+			t.Errorf("Case %d: expected %d steps but found %d", i, d.steps, steps)
+		}
+		if diff := alpha - d.alpha; diff > 0.0001 || diff < -0.0001 {
+			t.Errorf("Case %d: expected alpha %f but found %f", i, d.alpha, alpha)
+		}
+	}
+}
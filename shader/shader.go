@@ -0,0 +1,260 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shader compiles GLSL programs and caches the locations of their uniforms
+// and attributes, so custom effects can be used without changing the display package.
+package shader
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/hurricanerix/shade/gpu"
+)
+
+func init() {
+	// GLFW event handling must run on the main OS thread
+	runtime.LockOSThread()
+}
+
+// Attribute locations bound before linking every program created with New, so a
+// vertex array set up for one program can be drawn with any other.
+const (
+	MCVertexLoc  = 0
+	MCNormalLoc  = 1
+	MCTangentLoc = 2
+	TexCoord0Loc = 3
+)
+
+var attribLocations = map[string]uint32{
+	"MCVertex":  MCVertexLoc,
+	"MCNormal":  MCNormalLoc,
+	"MCTangent": MCTangentLoc,
+	"TexCoord0": TexCoord0Loc,
+}
+
+// Variable is an active uniform or attribute of a Program.
+type Variable struct {
+	Name     string
+	Location int32
+	// Type of the variable, for example gl.FLOAT_VEC3 or gl.SAMPLER_2D.
+	Type uint32
+	// Size is the number of elements for arrays, 1 otherwise.
+	Size int32
+}
+
+// Program is a linked GLSL program.
+type Program struct {
+	// ID of the program in OpenGL.
+	ID uint32
+	// Uniforms which are active in the program by name.
+	Uniforms map[string]Variable
+	// Attributes which are active in the program by name.
+	Attributes map[string]Variable
+	// reloaded callbacks, see OnReload
+	reloaded []func()
+}
+
+// programs which have been reflected by ID.
+var programs = map[uint32]*Program{}
+
+// New compiles vertexSource and fragmentSource, links them into a program and
+// reflects its active uniforms and attributes.
+func New(vertexSource, fragmentSource string) (*Program, error) {
+	id, err := Link(vertexSource, fragmentSource)
+	if err != nil {
+		return nil, err
+	}
+	return Lookup(id), nil
+}
+
+// Lookup returns the Program for an OpenGL program id, reflecting it the first
+// time it is seen.
+func Lookup(id uint32) *Program {
+	if p, ok := programs[id]; ok {
+		return p
+	}
+	p := Program{}
+	p.reflect(id)
+	return &p
+}
+
+// reflect the active variables of program id into p and register p under id.
+func (p *Program) reflect(id uint32) {
+	p.ID = id
+	p.Uniforms = activeVariables(id, gl.ACTIVE_UNIFORMS, gl.ACTIVE_UNIFORM_MAX_LENGTH, gl.GetActiveUniform, gl.GetUniformLocation)
+	p.Attributes = activeVariables(id, gl.ACTIVE_ATTRIBUTES, gl.ACTIVE_ATTRIBUTE_MAX_LENGTH, gl.GetActiveAttrib, gl.GetAttribLocation)
+	programs[id] = p
+}
+
+// Reload replaces the program with one built from new sources.  p is updated in
+// place so everything holding it draws with the new program.  If compiling or
+// linking fails the old program is kept and the error holds the compile log.
+func (p *Program) Reload(vertexSource, fragmentSource string) error {
+	id, err := Link(vertexSource, fragmentSource)
+	if err != nil {
+		return err
+	}
+	p.deleteProgram()
+	p.reflect(id)
+	for _, f := range p.reloaded {
+		f()
+	}
+	return nil
+}
+
+// Delete the program from OpenGL.  It must not be used afterwards.
+func (p *Program) Delete() {
+	if p.ID == 0 {
+		return
+	}
+	p.deleteProgram()
+	p.ID = 0
+	p.reloaded = nil
+}
+
+func (p *Program) deleteProgram() {
+	delete(programs, p.ID)
+	gl.DeleteProgram(p.ID)
+	gpu.Deleted(gpu.Program, p.ID)
+}
+
+// OnReload calls f after the program is successfully reloaded, for example to
+// upload uniforms which are not set on every draw.
+func (p *Program) OnReload(f func()) {
+	p.reloaded = append(p.reloaded, f)
+}
+
+func activeVariables(
+	program uint32,
+	countParam, maxLengthParam uint32,
+	getActive func(uint32, uint32, int32, *int32, *int32, *uint32, *uint8),
+	getLocation func(uint32, *uint8) int32,
+) map[string]Variable {
+	var count, maxLength int32
+	gl.GetProgramiv(program, countParam, &count)
+	gl.GetProgramiv(program, maxLengthParam, &maxLength)
+
+	vars := make(map[string]Variable, count)
+	buf := make([]uint8, maxLength+1)
+	for i := int32(0); i < count; i++ {
+		var length, size int32
+		var xtype uint32
+		getActive(program, uint32(i), int32(len(buf)), &length, &size, &xtype, &buf[0])
+		name := string(buf[:length])
+		// Arrays are reported as "name[0]".
+		name = strings.TrimSuffix(name, "[0]")
+		vars[name] = Variable{
+			Name:     name,
+			Location: getLocation(program, gl.Str(name+"\x00")),
+			Type:     xtype,
+			Size:     size,
+		}
+	}
+	return vars
+}
+
+// Use the program for following draw calls.
+func (p *Program) Use() {
+	gl.UseProgram(p.ID)
+}
+
+// Uniform returns the location of the named uniform, or -1 if it is not active.
+// Setting a uniform at location -1 is silently ignored by OpenGL.
+func (p *Program) Uniform(name string) int32 {
+	if v, ok := p.Uniforms[name]; ok {
+		return v.Location
+	}
+	return -1
+}
+
+// Attrib returns the location of the named attribute, or -1 if it is not active.
+func (p *Program) Attrib(name string) int32 {
+	if v, ok := p.Attributes[name]; ok {
+		return v.Location
+	}
+	return -1
+}
+
+// Link compiles vertexSource and fragmentSource and links them into a program.
+func Link(vertexSource, fragmentSource string) (uint32, error) {
+	vertShader, err := Compile(vertexSource, gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, fmt.Errorf("can not create vert shader: %s", err)
+	}
+
+	fragShader, err := Compile(fragmentSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		gl.DeleteShader(vertShader)
+		return 0, fmt.Errorf("can not create frag shader: %s", err)
+	}
+
+	program := gl.CreateProgram()
+	gl.AttachShader(program, vertShader)
+	gl.AttachShader(program, fragShader)
+	// Must happen before linking to have any effect.
+	for name, loc := range attribLocations {
+		gl.BindAttribLocation(program, loc, gl.Str(name+"\x00"))
+	}
+	gl.BindFragDataLocation(program, 0, gl.Str("FragColor\x00"))
+	gl.LinkProgram(program)
+
+	gl.DeleteShader(vertShader)
+	gl.DeleteShader(fragShader)
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
+
+		gl.DeleteProgram(program)
+		return 0, fmt.Errorf("failed to link program: %v", log)
+	}
+
+	gpu.Created(gpu.Program, program)
+	return program, nil
+}
+
+// Compile source as a shader of shaderType (gl.VERTEX_SHADER or gl.FRAGMENT_SHADER).
+func Compile(source string, shaderType uint32) (uint32, error) {
+	if !strings.HasSuffix(source, "\x00") {
+		source += "\x00"
+	}
+	shader := gl.CreateShader(shaderType)
+
+	csource := gl.Str(source)
+	gl.ShaderSource(shader, 1, &csource, nil)
+	gl.CompileShader(shader)
+
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
+
+		gl.DeleteShader(shader)
+		return 0, fmt.Errorf("failed to compile %v: %v", source, log)
+	}
+
+	return shader, nil
+}
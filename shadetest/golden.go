@@ -0,0 +1,132 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shadetest
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/hurricanerix/shade/display"
+)
+
+var update = flag.Bool("shadetest.update", false, "write rendered images as the new golden images")
+
+// GoldenDir is where Golden looks for golden images.
+var GoldenDir = "testdata"
+
+// TB is the subset of testing.TB used by Golden.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// Compare got to want, allowing each color channel of each pixel to differ by up to
+// tolerance.  The number of mismatched pixels is returned along with a diff image
+// where mismatches are red and matching pixels are a faded copy of want.
+func Compare(got, want image.Image, tolerance uint8) (*image.RGBA, int, error) {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Dx() != wb.Dx() || gb.Dy() != wb.Dy() {
+		return nil, 0, fmt.Errorf("image size %dx%d does not match %dx%d",
+			gb.Dx(), gb.Dy(), wb.Dx(), wb.Dy())
+	}
+
+	diff := image.NewRGBA(image.Rect(0, 0, wb.Dx(), wb.Dy()))
+	mismatched := 0
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			g := color.RGBAModel.Convert(got.At(gb.Min.X+x, gb.Min.Y+y)).(color.RGBA)
+			w := color.RGBAModel.Convert(want.At(wb.Min.X+x, wb.Min.Y+y)).(color.RGBA)
+			if channelDiff(g.R, w.R) > tolerance ||
+				channelDiff(g.G, w.G) > tolerance ||
+				channelDiff(g.B, w.B) > tolerance ||
+				channelDiff(g.A, w.A) > tolerance {
+				mismatched++
+				diff.SetRGBA(x, y, color.RGBA{255, 0, 0, 255})
+				continue
+			}
+			l := uint8((uint16(w.R) + uint16(w.G) + uint16(w.B)) / 3 / 4)
+			diff.SetRGBA(x, y, color.RGBA{l, l, l, 255})
+		}
+	}
+	return diff, mismatched, nil
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// Golden compares got to the golden image GoldenDir/name.png, failing t if any
+// pixel differs by more than tolerance.  On failure the rendered image and a diff
+// are written next to the golden image as name.actual.png and name.diff.png.
+func Golden(t TB, name string, got image.Image, tolerance uint8) {
+	t.Helper()
+	path := filepath.Join(GoldenDir, name+".png")
+
+	if *update {
+		if err := display.SavePNG(path, got); err != nil {
+			t.Fatalf("could not update golden image: %v", err)
+		}
+		return
+	}
+
+	want, err := LoadPNG(path)
+	if err != nil {
+		t.Fatalf("could not load golden image (run with -shadetest.update to create it): %v", err)
+		return
+	}
+
+	diff, mismatched, err := Compare(got, want, tolerance)
+	if err != nil {
+		t.Errorf("%s: %v", name, err)
+		return
+	}
+	if mismatched == 0 {
+		return
+	}
+
+	actualPath := filepath.Join(GoldenDir, name+".actual.png")
+	diffPath := filepath.Join(GoldenDir, name+".diff.png")
+	if err := display.SavePNG(actualPath, got); err != nil {
+		t.Errorf("could not save rendered image: %v", err)
+	}
+	if err := display.SavePNG(diffPath, diff); err != nil {
+		t.Errorf("could not save diff image: %v", err)
+	}
+	t.Errorf("%s: %d pixels differ from golden image by more than %d, see %s",
+		name, mismatched, tolerance, diffPath)
+}
+
+// LoadPNG from path.
+func LoadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode file %s: %v", path, err)
+	}
+	return img, nil
+}
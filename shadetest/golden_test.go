@@ -0,0 +1,131 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shadetest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func solid(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestCompareTolerance(t *testing.T) {
+	want := solid(4, 4, color.RGBA{100, 100, 100, 255})
+	got := solid(4, 4, color.RGBA{102, 98, 100, 255})
+	got.SetRGBA(1, 2, color.RGBA{200, 100, 100, 255})
+
+	diff, mismatched, err := Compare(got, want, 2)
+	if err != nil {
+		t.Fatal("Compare returned unexpected error", err)
+	}
+	if mismatched != 1 {
+		t.Error("Expected 1 mismatched pixel but found", mismatched)
+	}
+	if c := diff.RGBAAt(1, 2); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Error("Expected mismatched pixel to be red in diff but found", c)
+	}
+
+	_, mismatched, _ = Compare(got, want, 100)
+	if mismatched != 0 {
+		t.Error("Expected 0 mismatched pixels with large tolerance but found", mismatched)
+	}
+}
+
+func TestCompareSize(t *testing.T) {
+	_, _, err := Compare(solid(4, 4, color.RGBA{}), solid(4, 5, color.RGBA{}), 0)
+	if err == nil {
+		t.Error("Expected Compare of different sized images to return an error")
+	}
+}
+
+// recorder is a TB which records failures instead of failing the test.
+type recorder struct {
+	errors []string
+	fatals []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.fatals = append(r.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(d string, u bool) {
+		GoldenDir, *update = d, u
+	}(GoldenDir, *update)
+	GoldenDir = dir
+
+	want := solid(4, 4, color.RGBA{100, 100, 100, 255})
+	got := solid(4, 4, color.RGBA{100, 100, 100, 255})
+	got.SetRGBA(1, 2, color.RGBA{200, 100, 100, 255})
+
+	tests := []struct {
+		name   string
+		update bool
+		img    image.Image
+		errors int
+		fatals int
+		files  []string
+	}{
+		{"missing", false, want, 0, 1, nil},
+		{"update", true, want, 0, 0, []string{"update.png"}},
+		{"update", false, want, 0, 0, nil},
+		{"update", false, got, 1, 0, []string{"update.actual.png", "update.diff.png"}},
+	}
+	for _, tt := range tests {
+		*update = tt.update
+		r := recorder{}
+		Golden(&r, tt.name, tt.img, 2)
+		if len(r.errors) != tt.errors || len(r.fatals) != tt.fatals {
+			t.Error("Expected", tt.errors, "errors and", tt.fatals, "fatals for", tt.name, "but found", r.errors, r.fatals)
+		}
+		for _, f := range tt.files {
+			if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+				t.Error("Expected Golden to write", f, "but found", err)
+			}
+		}
+	}
+
+	diff, err := LoadPNG(filepath.Join(dir, "update.diff.png"))
+	if err != nil {
+		t.Fatal("Expected a diff image but found", err)
+	}
+	if c := color.RGBAModel.Convert(diff.At(1, 2)); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Error("Expected mismatched pixel to be red in the saved diff but found", c)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shadetest
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade/camera"
+	"github.com/hurricanerix/shade/display"
+	"github.com/hurricanerix/shade/light"
+	"github.com/hurricanerix/shade/normalmap"
+	"github.com/hurricanerix/shade/sprite"
+)
+
+func TestMain(m *testing.M) {
+	Main(m)
+}
+
+// dome is a size x size height map of a dome filling it.
+func dome(size int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	r := float64(size) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := (float64(x)+0.5-r)/r, (float64(y)+0.5-r)/r
+			if d := dx*dx + dy*dy; d < 1 {
+				img.SetGray(x, y, color.Gray{uint8(255 * math.Sqrt(1-d))})
+			}
+		}
+	}
+	return img
+}
+
+// litScene draws an orange tile, bumped by the normal map of a dome, lit from its
+// top left.
+type litScene struct {
+	tile *sprite.Context
+}
+
+func (s *litScene) Setup(screen *display.Context, cam *camera.Context) error {
+	orange := image.NewUniform(color.RGBA{255, 160, 64, 255})
+	colorMap := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			colorMap.Set(x, y, orange.C)
+		}
+	}
+	normalMap := normalmap.Generate(dome(32), normalmap.Options{})
+
+	var err error
+	if s.tile, err = sprite.New(colorMap, normalMap, 1, 1); err != nil {
+		return err
+	}
+	return s.tile.Bind(screen.Program)
+}
+
+func (s *litScene) Frame(n int, dt float32) {
+	s.tile.Draw(mgl32.Vec3{16, 16, 0}, &sprite.Effects{
+		Scale:          mgl32.Vec3{1, 1, 1},
+		EnableLighting: true,
+		AmbientColor:   mgl32.Vec4{0.2, 0.2, 0.2, 1},
+		Light: light.Positional{
+			Pos:   mgl32.Vec3{8, 56, 40},
+			Color: mgl32.Vec4{1, 1, 1, 1},
+			Power: 2000,
+		},
+	})
+}
+
+func TestLitNormalMappedSprite(t *testing.T) {
+	s := litScene{}
+	img, err := Render(&s, Options{Width: 64, Height: 64})
+	if err != nil {
+		t.Skip(err)
+	}
+	Do(s.tile.Release)
+	Golden(t, "lit-normal-mapped", img, 2)
+}
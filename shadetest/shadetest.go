@@ -0,0 +1,185 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shadetest renders scripted scenes offscreen and compares the result
+// against golden images, so rendering regressions are caught by "go test".
+//
+// OpenGL calls must all be made from the main OS thread, so a test package using
+// shadetest must hand control of it over in TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		shadetest.Main(m)
+//	}
+//
+//	func TestLighting(t *testing.T) {
+//		img, err := shadetest.Render(&lightingScene{}, shadetest.Options{Frames: 10})
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		shadetest.Golden(t, "lighting", img, 2)
+//	}
+//
+// Golden images live in testdata/<name>.png and are (re)written by running the
+// tests with -shadetest.update.
+package shadetest
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"runtime"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade/camera"
+	"github.com/hurricanerix/shade/display"
+)
+
+func init() {
+	// GLFW event handling must run on the main OS thread
+	runtime.LockOSThread()
+}
+
+// Scene is a scripted scene rendered by Render.
+type Scene interface {
+	// Setup is called once before the first frame, after the camera is bound.
+	Setup(screen *display.Context, cam *camera.Context) error
+	// Frame updates and draws frame n, dt milliseconds after the previous one.
+	Frame(n int, dt float32)
+}
+
+// Options for Render.
+type Options struct {
+	// Width of the framebuffer in pixels, defaults to 640.
+	Width int
+	// Height of the framebuffer in pixels, defaults to 480.
+	Height int
+	// Frames to render before capturing, defaults to 1.
+	Frames int
+	// FrameTime passed to Scene.Frame as dt in milliseconds, defaults to 1000/30.
+	FrameTime float32
+	// Background color the framebuffer is cleared to before each frame.
+	Background [3]float32
+}
+
+var (
+	work    = make(chan func())
+	running bool
+	screens = map[[2]int]*display.Context{}
+	// cameras bound to the screens, which are kept as they can't be unbound
+	cameras = map[[2]int]*camera.Context{}
+)
+
+// Main runs m's tests while serving OpenGL calls on the main OS thread, then exits.
+// It should be called from TestMain.
+func Main(m interface {
+	Run() int
+}) {
+	running = true
+	done := make(chan int)
+	go func() {
+		done <- m.Run()
+	}()
+	for {
+		select {
+		case f := <-work:
+			f()
+		case code := <-done:
+			os.Exit(code)
+		}
+	}
+}
+
+// Do f on the main OS thread and wait for it to finish, for OpenGL calls a test
+// makes outside of a Scene, such as releasing what the scene created.
+func Do(f func()) {
+	finished := make(chan struct{})
+	work <- func() {
+		f()
+		close(finished)
+	}
+	<-finished
+}
+
+// Render scene offscreen for opts.Frames frames and return the last one.
+func Render(scene Scene, opts Options) (*image.RGBA, error) {
+	if !running {
+		return nil, fmt.Errorf("shadetest: Main must be called from TestMain before Render")
+	}
+	if opts.Width == 0 {
+		opts.Width = 640
+	}
+	if opts.Height == 0 {
+		opts.Height = 480
+	}
+	if opts.Frames == 0 {
+		opts.Frames = 1
+	}
+	if opts.FrameTime == 0 {
+		opts.FrameTime = 1000.0 / 30.0
+	}
+
+	var img *image.RGBA
+	var err error
+	Do(func() {
+		img, err = render(scene, opts)
+	})
+	return img, err
+}
+
+func render(scene Scene, opts Options) (*image.RGBA, error) {
+	size := [2]int{opts.Width, opts.Height}
+	screen, ok := screens[size]
+	if !ok {
+		var err error
+		screen, err = display.SetModeOffscreen("shadetest", opts.Width, opts.Height)
+		if err != nil {
+			return nil, fmt.Errorf("shadetest: could not create display: %v", err)
+		}
+		screens[size] = screen
+	}
+	screen.Window.MakeContextCurrent()
+
+	cam, ok := cameras[size]
+	if !ok {
+		var err error
+		cam, err = camera.New(screen)
+		if err != nil {
+			return nil, err
+		}
+		cameras[size] = cam
+	}
+	// Each scene starts with the camera as it was created.
+	cam.Offset = mgl32.Vec2{}
+	cam.TopStop, cam.BottomStop, cam.LeftStop, cam.RightStop = 0, 0, 0, 0
+	cam.Pos = mgl32.Vec3{}
+	cam.Bind(screen.Program)
+	cam.Move(mgl32.Vec3{})
+
+	if err := scene.Setup(screen, cam); err != nil {
+		return nil, fmt.Errorf("shadetest: scene setup failed: %v", err)
+	}
+
+	for n := 0; n < opts.Frames; n++ {
+		screen.Fill(opts.Background[0], opts.Background[1], opts.Background[2])
+		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+		scene.Frame(n, opts.FrameTime)
+		if n < opts.Frames-1 {
+			screen.Flip()
+		}
+	}
+	img := screen.ReadPixels()
+	screen.Flip()
+	return img, nil
+}
@@ -0,0 +1,392 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sheet loads sprite sheets described by JSON, as exported by Aseprite and
+// TexturePacker, where each frame has its own rectangle on the sheet.
+package sheet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade/gen"
+	"github.com/hurricanerix/shade/sprite"
+)
+
+// DefaultDuration of frames in milliseconds, for sheets which do not have any.
+const DefaultDuration = 100
+
+// Frame of a sprite sheet.
+type Frame struct {
+	Name string
+	// Bounds of the frame on the sheet in pixels.  If the frame was trimmed of
+	// transparent pixels this is smaller than Size.
+	Bounds image.Rectangle
+	// Offset of Bounds from the top left corner of the untrimmed frame.
+	Offset image.Point
+	// Size of the untrimmed frame.
+	Size image.Point
+	// Duration the frame is shown for in milliseconds.
+	Duration float32
+	sprite   *sprite.Context
+}
+
+// Sheet of frames and the animations made of them.
+type Sheet struct {
+	// Image file of the sheet, relative to the JSON file.
+	Image  string
+	Frames []Frame
+	// Animations by name.  Their frames are indexes into Frames, see DrawFrame.
+	Animations map[string]*sprite.Animation
+	ColorMap   image.Image
+	// NormalMap is nil if the sheet does not have one.
+	NormalMap image.Image
+	// Sampling of the sheet's textures, used when they are bound.  Mipmaps bleed
+	// between frames unless Sampling.MipLevels is limited to the space between
+	// them.
+	Sampling sprite.Sampling
+	page     *sprite.Context
+}
+
+type rect struct {
+	X, Y, W, H int
+}
+
+type jsonFrame struct {
+	Filename         string
+	Frame            rect
+	Rotated          bool
+	SpriteSourceSize rect
+	SourceSize       struct{ W, H int }
+	Duration         float32
+}
+
+type jsonSheet struct {
+	Frames json.RawMessage
+	Meta   struct {
+		Image     string
+		FrameTags []struct {
+			Name      string
+			From      int
+			To        int
+			Direction string
+			Repeat    string
+		}
+	}
+	// Animations of TexturePacker sheets exported for PixiJS, as frame names.
+	Animations map[string][]string
+}
+
+// Decode a sheet in the JSON hash or JSON array format of Aseprite or TexturePacker.
+// Aseprite frame tags and TexturePacker animations become Animations.  The images
+// are not loaded, see SetMaps.
+func Decode(r io.Reader) (*Sheet, error) {
+	var js jsonSheet
+	if err := json.NewDecoder(r).Decode(&js); err != nil {
+		return nil, fmt.Errorf("could not decode sheet: %v", err)
+	}
+	frames, err := decodeFrames(js.Frames)
+	if err != nil {
+		return nil, err
+	}
+
+	s := Sheet{
+		Image:      js.Meta.Image,
+		Animations: map[string]*sprite.Animation{},
+	}
+	names := make(map[string]int, len(frames))
+	for i, f := range frames {
+		if f.Rotated {
+			return nil, fmt.Errorf("could not decode frame %s: rotated frames are not supported", f.Filename)
+		}
+		size := image.Pt(f.SourceSize.W, f.SourceSize.H)
+		if size == (image.Point{}) {
+			size = image.Pt(f.Frame.W, f.Frame.H)
+		}
+		duration := f.Duration
+		if duration <= 0 {
+			duration = DefaultDuration
+		}
+		s.Frames = append(s.Frames, Frame{
+			Name:     f.Filename,
+			Bounds:   image.Rect(f.Frame.X, f.Frame.Y, f.Frame.X+f.Frame.W, f.Frame.Y+f.Frame.H),
+			Offset:   image.Pt(f.SpriteSourceSize.X, f.SpriteSourceSize.Y),
+			Size:     size,
+			Duration: duration,
+		})
+		names[f.Filename] = i
+	}
+
+	for _, tag := range js.Meta.FrameTags {
+		if tag.From < 0 || tag.To >= len(s.Frames) || tag.From > tag.To {
+			return nil, fmt.Errorf("could not decode tag %s: frames %d to %d out of range", tag.Name, tag.From, tag.To)
+		}
+		var indexes []int
+		for i := tag.From; i <= tag.To; i++ {
+			indexes = append(indexes, i)
+		}
+		a := s.animation(tag.Name, indexes)
+		switch tag.Direction {
+		case "reverse", "pingpong_reverse":
+			for i, j := 0, len(a.Frames)-1; i < j; i, j = i+1, j-1 {
+				a.Frames[i], a.Frames[j] = a.Frames[j], a.Frames[i]
+			}
+		}
+		if strings.HasPrefix(tag.Direction, "pingpong") {
+			a.Mode = sprite.PingPong
+		} else if tag.Repeat == "1" {
+			a.Mode = sprite.Once
+		}
+	}
+
+	for name, frameNames := range js.Animations {
+		var indexes []int
+		for _, n := range frameNames {
+			i, ok := names[n]
+			if !ok {
+				return nil, fmt.Errorf("could not decode animation %s: no frame %s", name, n)
+			}
+			indexes = append(indexes, i)
+		}
+		s.animation(name, indexes)
+	}
+	return &s, nil
+}
+
+// animation of frames indexes, added to the sheet as name.
+func (s *Sheet) animation(name string, indexes []int) *sprite.Animation {
+	a := sprite.Animation{Name: name}
+	for _, i := range indexes {
+		a.Frames = append(a.Frames, sprite.AnimationFrame{
+			Frame:    mgl32.Vec2{float32(i), 0},
+			Duration: s.Frames[i].Duration,
+		})
+	}
+	s.Animations[name] = &a
+	return &a
+}
+
+// decodeFrames from a JSON array, or a JSON hash keeping the order of its keys.
+func decodeFrames(raw json.RawMessage) ([]jsonFrame, error) {
+	var frames []jsonFrame
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("could not decode sheet: no frames")
+	}
+	if raw[0] == '[' {
+		if err := json.Unmarshal(raw, &frames); err != nil {
+			return nil, fmt.Errorf("could not decode frames: %v", err)
+		}
+		return frames, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("could not decode frames: %v", err)
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("could not decode frames: %v", err)
+		}
+		var f jsonFrame
+		if err := dec.Decode(&f); err != nil {
+			return nil, fmt.Errorf("could not decode frame %v: %v", t, err)
+		}
+		f.Filename = t.(string)
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// Load the sheet at path and its image.  A normal map is loaded too if there is one
+// named like the image with ".normal" before the extension, as in the assets
+// directory.
+func Load(path string) (*Sheet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file %s: %v", path, err)
+	}
+	defer f.Close()
+	s, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not load %s: %v", path, err)
+	}
+
+	imagePath := filepath.Join(filepath.Dir(path), s.Image)
+	colorMap, err := sprite.Load(imagePath)
+	if err != nil {
+		return nil, err
+	}
+	var normalMap image.Image
+	normalPath := normalName(imagePath)
+	if _, err := os.Stat(normalPath); err == nil {
+		if normalMap, err = sprite.Load(normalPath); err != nil {
+			return nil, err
+		}
+	}
+	return s, s.SetMaps(colorMap, normalMap)
+}
+
+// LoadAsset loads the sheet and its images like Load, from the assets compiled in
+// by bindata.sh.
+func LoadAsset(name string) (*Sheet, error) {
+	data, err := gen.Asset(name)
+	if err != nil {
+		return nil, fmt.Errorf("could not load asset %s: %v", name, err)
+	}
+	s, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not load %s: %v", name, err)
+	}
+
+	imageName := path.Join(path.Dir(name), s.Image)
+	colorMap, err := sprite.LoadAsset(imageName)
+	if err != nil {
+		return nil, err
+	}
+	var normalMap image.Image
+	if _, err := gen.Asset(normalName(imageName)); err == nil {
+		if normalMap, err = sprite.LoadAsset(normalName(imageName)); err != nil {
+			return nil, err
+		}
+	}
+	return s, s.SetMaps(colorMap, normalMap)
+}
+
+// normalName of the normal map for the image file name.
+func normalName(name string) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + ".normal" + ext
+}
+
+// SetMaps the frames are drawn from.  normalMap may be nil, and must otherwise be
+// the same size as colorMap.
+func (s *Sheet) SetMaps(colorMap, normalMap image.Image) error {
+	if normalMap != nil && normalMap.Bounds().Size() != colorMap.Bounds().Size() {
+		return fmt.Errorf("normal map is %v, not the size of the sheet %v", normalMap.Bounds().Size(), colorMap.Bounds().Size())
+	}
+	page, err := sprite.New(colorMap, normalMap, 1, 1)
+	if err != nil {
+		return err
+	}
+	min := colorMap.Bounds().Min
+	for i := range s.Frames {
+		f := &s.Frames[i]
+		if f.sprite, err = page.Region(f.Bounds.Add(min), 1, 1); err != nil {
+			return fmt.Errorf("could not use frame %s: %v", f.Name, err)
+		}
+	}
+	s.ColorMap = colorMap
+	s.NormalMap = normalMap
+	s.page = page
+	return nil
+}
+
+// Bind the sheet's textures to OpenGL, see sprite.Context.Bind.
+func (s *Sheet) Bind(program uint32) error {
+	if s.page == nil {
+		return fmt.Errorf("sheet has no maps")
+	}
+	// Frames share the page's textures, so they are uploaded once.
+	s.page.Sampling = s.Sampling
+	if s.Sampling.Mipmaps && s.Sampling.MipLevels == 0 {
+		fmt.Println("Warning: mipmaps of", s.Image, "bleed between its frames, limit Sampling.MipLevels to the space between them")
+	}
+	if err := s.page.Bind(program); err != nil {
+		return err
+	}
+	for i := range s.Frames {
+		if err := s.Frames[i].sprite.Bind(program); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetBatch the sheet's frames are drawn through, see sprite.Context.Batch.
+func (s *Sheet) SetBatch(b *sprite.Batch) {
+	if s.page == nil {
+		return
+	}
+	for i := range s.Frames {
+		s.Frames[i].sprite.Batch = b
+	}
+}
+
+// Release the sheet's OpenGL objects.
+func (s *Sheet) Release() {
+	if s.page == nil {
+		return
+	}
+	for i := range s.Frames {
+		s.Frames[i].sprite.Release()
+	}
+	s.page.Release()
+}
+
+// Draw the first frame with the bottom left corner of its untrimmed size at pos.
+func (s *Sheet) Draw(pos mgl32.Vec3, e *sprite.Effects) {
+	s.DrawFrame(mgl32.Vec2{0, 0}, pos, e)
+}
+
+// DrawFrame frame[0] of the sheet, an index into Frames like the frames of
+// Animations, with the bottom left corner of its untrimmed size at pos.
+func (s *Sheet) DrawFrame(frame mgl32.Vec2, pos mgl32.Vec3, e *sprite.Effects) {
+	i := int(frame[0])
+	if i < 0 || i >= len(s.Frames) {
+		return
+	}
+	f := s.Frames[i]
+	if f.sprite == nil {
+		return
+	}
+	// Offset is from the top, pos is the bottom.
+	x := float32(f.Offset.X)
+	y := float32(f.Size.Y - f.Offset.Y - f.Bounds.Dy())
+	if e == nil {
+		pos[0] += x
+		pos[1] += y
+		f.sprite.DrawFrame(mgl32.Vec2{0, 0}, pos, e)
+		return
+	}
+
+	// Flipping mirrors the trimmed frame within its untrimmed size.
+	if e.FlipX {
+		x = float32(f.Size.X-f.Bounds.Dx()) - x
+	}
+	if e.FlipY {
+		y = float32(f.Size.Y-f.Bounds.Dy()) - y
+	}
+	trimmed := *e
+	w, h := float32(f.Bounds.Dx()), float32(f.Bounds.Dy())
+	if w > 0 && h > 0 {
+		// Pivot is relative to the untrimmed size.
+		trimmed.Pivot = mgl32.Vec2{
+			(e.Pivot[0]*float32(f.Size.X) - x) / w,
+			(e.Pivot[1]*float32(f.Size.Y) - y) / h,
+		}
+	}
+	pos[0] += x * e.Scale[0]
+	pos[1] += y * e.Scale[1]
+	f.sprite.DrawFrame(mgl32.Vec2{0, 0}, pos, &trimmed)
+}
@@ -0,0 +1,173 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sheet
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade/sprite"
+)
+
+// Aseprite's JSON hash format, frames are in key order.
+const aseprite = `{ "frames": {
+   "hero 1.aseprite": {
+    "frame": { "x": 32, "y": 0, "w": 20, "h": 28 },
+    "rotated": false,
+    "trimmed": true,
+    "spriteSourceSize": { "x": 6, "y": 4, "w": 20, "h": 28 },
+    "sourceSize": { "w": 32, "h": 32 },
+    "duration": 120
+   },
+   "hero 0.aseprite": {
+    "frame": { "x": 0, "y": 0, "w": 32, "h": 32 },
+    "rotated": false,
+    "trimmed": false,
+    "spriteSourceSize": { "x": 0, "y": 0, "w": 32, "h": 32 },
+    "sourceSize": { "w": 32, "h": 32 },
+    "duration": 100
+   },
+   "hero 2.aseprite": {
+    "frame": { "x": 0, "y": 32, "w": 32, "h": 32 },
+    "rotated": false,
+    "trimmed": false,
+    "spriteSourceSize": { "x": 0, "y": 0, "w": 32, "h": 32 },
+    "sourceSize": { "w": 32, "h": 32 },
+    "duration": 80
+   }
+ },
+ "meta": {
+  "app": "http://www.aseprite.org/",
+  "image": "hero.png",
+  "size": { "w": 64, "h": 64 },
+  "frameTags": [
+   { "name": "walk", "from": 0, "to": 2, "direction": "pingpong" },
+   { "name": "back", "from": 1, "to": 2, "direction": "reverse", "repeat": "1" }
+  ]
+ }
+}`
+
+// TexturePacker's JSON array format with PixiJS animations.
+const texturePacker = `{"frames": [
+{
+	"filename": "coin1.png",
+	"frame": {"x":0,"y":0,"w":16,"h":16},
+	"rotated": false,
+	"trimmed": false,
+	"spriteSourceSize": {"x":0,"y":0,"w":16,"h":16},
+	"sourceSize": {"w":16,"h":16}
+},
+{
+	"filename": "coin2.png",
+	"frame": {"x":16,"y":0,"w":8,"h":16},
+	"rotated": false,
+	"trimmed": true,
+	"spriteSourceSize": {"x":4,"y":0,"w":8,"h":16},
+	"sourceSize": {"w":16,"h":16}
+}],
+"animations": {
+	"spin": ["coin1.png", "coin2.png", "coin1.png"]
+},
+"meta": {
+	"app": "https://www.codeandweb.com/texturepacker",
+	"image": "coins.png",
+	"size": {"w":24,"h":16},
+	"scale": "1"
+}
+}`
+
+func TestDecodeAseprite(t *testing.T) {
+	s, err := Decode(strings.NewReader(aseprite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Image != "hero.png" {
+		t.Error("Expected image hero.png but found", s.Image)
+	}
+	if len(s.Frames) != 3 {
+		t.Fatalf("Expected 3 frames but found %d", len(s.Frames))
+	}
+
+	f := s.Frames[0]
+	if f.Name != "hero 1.aseprite" {
+		t.Error("Expected frames in the order of the file but found", f.Name, "first")
+	}
+	if f.Bounds != image.Rect(32, 0, 52, 28) || f.Offset != image.Pt(6, 4) || f.Size != image.Pt(32, 32) {
+		t.Error("Expected trimmed frame but found", f.Bounds, f.Offset, f.Size)
+	}
+	if f.Duration != 120 {
+		t.Error("Expected duration 120 but found", f.Duration)
+	}
+
+	walk := s.Animations["walk"]
+	if walk == nil || walk.Mode != sprite.PingPong || len(walk.Frames) != 3 {
+		t.Fatal("Expected ping pong walk animation with 3 frames but found", walk)
+	}
+	if walk.Frames[2].Frame != (mgl32.Vec2{2, 0}) || walk.Frames[2].Duration != 80 {
+		t.Error("Expected walk's last frame to be frame 2 for 80ms but found", walk.Frames[2])
+	}
+
+	back := s.Animations["back"]
+	if back == nil || back.Mode != sprite.Once || len(back.Frames) != 2 {
+		t.Fatal("Expected back animation played once with 2 frames but found", back)
+	}
+	if back.Frames[0].Frame != (mgl32.Vec2{2, 0}) {
+		t.Error("Expected back to be reversed but found", back.Frames)
+	}
+}
+
+func TestDecodeTexturePacker(t *testing.T) {
+	s, err := Decode(strings.NewReader(texturePacker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Frames) != 2 {
+		t.Fatalf("Expected 2 frames but found %d", len(s.Frames))
+	}
+	if s.Frames[1].Duration != DefaultDuration {
+		t.Error("Expected the default duration but found", s.Frames[1].Duration)
+	}
+
+	spin := s.Animations["spin"]
+	if spin == nil || spin.Mode != sprite.Loop || len(spin.Frames) != 3 {
+		t.Fatal("Expected looping spin animation with 3 frames but found", spin)
+	}
+	if spin.Frames[1].Frame != (mgl32.Vec2{1, 0}) {
+		t.Error("Expected spin's second frame to be frame 1 but found", spin.Frames[1])
+	}
+
+	if err := s.SetMaps(image.NewRGBA(image.Rect(0, 0, 24, 16)), nil); err != nil {
+		t.Fatal(err)
+	}
+	if s.Frames[1].sprite.Width != 8 {
+		t.Error("Expected frame sprite 8 pixels wide but found", s.Frames[1].sprite.Width)
+	}
+	if err := s.SetMaps(image.NewRGBA(image.Rect(0, 0, 24, 16)), image.NewRGBA(image.Rect(0, 0, 8, 8))); err == nil {
+		t.Error("Expected an error for a normal map of a different size")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	rotated := strings.Replace(texturePacker, `"rotated": false`, `"rotated": true`, 1)
+	if _, err := Decode(strings.NewReader(rotated)); err == nil {
+		t.Error("Expected an error for a rotated frame")
+	}
+	missing := strings.Replace(texturePacker, `"coin2.png", "coin1.png"]`, `"coin3.png"]`, 1)
+	if _, err := Decode(strings.NewReader(missing)); err == nil {
+		t.Error("Expected an error for an animation of a missing frame")
+	}
+}
@@ -16,12 +16,12 @@
 package ghost
 
 import (
-	"math"
 	"runtime"
 
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/hurricanerix/shade/entity"
 	"github.com/hurricanerix/shade/light"
+	"github.com/hurricanerix/shade/normalmap"
 	"github.com/hurricanerix/shade/shapes"
 	"github.com/hurricanerix/shade/sprite"
 )
@@ -40,8 +40,7 @@ type Ghost struct {
 	AmbientColor mgl32.Vec4
 	dx           float32
 	looking      int
-	fx           float32
-	frame        float32
+	float        *sprite.Animator
 	dl           float32
 }
 
@@ -52,7 +51,9 @@ func New() *Ghost {
 		// TODO: move sprite loading out side of ghost
 		panic(err)
 	}
-	s, err := sprite.New(i, nil, 6, 3)
+	// The ghost has no hand painted normal map, so one is generated from its art.
+	n := normalmap.Generate(i, normalmap.Options{})
+	s, err := sprite.New(i, n, 6, 3)
 	if err != nil {
 		panic(err)
 	}
@@ -73,7 +74,9 @@ func New() *Ghost {
 	c.Light = &light
 
 	c.dx = 0.3
-	c.fx = 0.02
+	// Each frame of the bottom row is two tiles wide.
+	c.float = sprite.NewAnimator(sprite.NewAnimation("float", sprite.Loop, 50,
+		mgl32.Vec2{0, 2}, mgl32.Vec2{2, 2}, mgl32.Vec2{4, 2}))
 
 	return &c
 }
@@ -94,7 +97,7 @@ func (c *Ghost) Bind(program uint32) error {
 // Update TODO doc
 func (c *Ghost) Update(dt float32, g []entity.Collider) {
 	c.pos[0] += c.dx * dt
-	c.frame += c.fx * dt
+	c.float.Update(dt / 1000)
 	if c.pos[0] >= 400 {
 		c.dx = 0
 		c.looking = -1
@@ -132,7 +135,7 @@ func (c *Ghost) Draw() {
 		eyes = 4
 	}
 
-	f := int(math.Mod(float64(int(c.frame)), 3)) * 2
+	f := c.float.Frame()
 
 	c.Sprite.DrawFrame(mgl32.Vec2{float32(eyes), 0}, mgl32.Vec3{left, top, 0.0}, nil)
 	c.Sprite.DrawFrame(mgl32.Vec2{float32(eyes) + 1, 0}, mgl32.Vec3{right, top, 0.0}, nil)
@@ -140,6 +143,6 @@ func (c *Ghost) Draw() {
 	c.Sprite.DrawFrame(mgl32.Vec2{0, 1}, mgl32.Vec3{left, middle, 0.0}, nil)
 	c.Sprite.DrawFrame(mgl32.Vec2{1, 1}, mgl32.Vec3{right, middle, 0.0}, nil)
 
-	c.Sprite.DrawFrame(mgl32.Vec2{float32(f), 2}, mgl32.Vec3{left, bottom, 0.0}, nil)
-	c.Sprite.DrawFrame(mgl32.Vec2{float32(f) + 1, 2}, mgl32.Vec3{right, bottom, 0.0}, nil)
+	c.Sprite.DrawFrame(f, mgl32.Vec3{left, bottom, 0.0}, nil)
+	c.Sprite.DrawFrame(mgl32.Vec2{f[0] + 1, f[1]}, mgl32.Vec3{right, bottom, 0.0}, nil)
 }
@@ -11,7 +11,7 @@
 // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 // See the License for the specific language governing permissions and
 // limitations under the License.
-// Package splash TODO doc
+// Package splash shows the Shade SDK splash screen.
 
 package splash
 
@@ -19,16 +19,16 @@ import (
 	_ "image/png"
 	"runtime"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
-	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade"
 	"github.com/hurricanerix/shade/camera"
 	"github.com/hurricanerix/shade/display"
 	"github.com/hurricanerix/shade/events"
 	"github.com/hurricanerix/shade/fonts"
+	"github.com/hurricanerix/shade/scene"
 	"github.com/hurricanerix/shade/splash/ghost"
 	"github.com/hurricanerix/shade/sprite"
-	"github.com/hurricanerix/shade/time/clock"
 )
 
 func init() {
@@ -36,76 +36,111 @@ func init() {
 	runtime.LockOSThread()
 }
 
-func Main(screen *display.Context) {
-	cam, err := camera.New()
+// How long the splash screen is shown in seconds.
+const duration = 3.0
+
+const msg = "Shade SDK"
+
+// Scene shows the splash screen, then replaces itself with the next scene.
+type Scene struct {
+	screen  *display.Context
+	manager *scene.Manager
+	next    scene.Scene
+	cam     *camera.Context
+	font    *fonts.Context
+	ghost   *ghost.Ghost
+	total   float32
+}
+
+// New splash screen for manager, which is replaced by next when it is done, or
+// popped if next is nil.
+func New(screen *display.Context, manager *scene.Manager, next scene.Scene) (*Scene, error) {
+	cam, err := camera.New(screen)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	cam.Bind(screen.Program)
-
-	clock, err := clock.New()
+	font, err := loadFont()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	return &Scene{
+		screen:  screen,
+		manager: manager,
+		next:    next,
+		cam:     cam,
+		font:    font,
+	}, nil
+}
 
-	font, err := loadFont()
+// Main shows the splash screen on screen, returning when it is done.
+func Main(screen *display.Context) {
+	m := scene.New()
+	s, err := New(screen, m, nil)
 	if err != nil {
 		panic(err)
 	}
-	font.Sprite.Bind(screen.Program)
-	msg := "Shade SDK"
-
-	g := ghost.New()
-	g.Bind(screen.Program)
-
-	total := float32(0.0)
-	for running := true; running; {
-		dt := clock.Tick(30)
-		total += dt
-
-		screen.Fill(0.0, 0.0, 0.0)
-
-		// TODO move this somewhere else (maybe a Clear method of display
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-
-		for _, event := range events.Get() {
-			if event.Type == events.KeyUp && event.Key == glfw.KeyEscape {
-				// Send window close event
-				screen.Close()
-			}
-			if event.Type == events.WindowClose {
-				// Handle window close
-				running = false
-			}
-		}
-
-		g.Update(dt, nil)
-
-		effect := sprite.Effects{
-			Scale:          mgl32.Vec3{1.0, 1.0, 1.0},
-			Tint:           mgl32.Vec4{1.0, 1.0, 1.0, 0.0},
-			EnableLighting: true,
-			AmbientColor:   g.AmbientColor,
-			Light:          *g.Light}
-		_, h := font.SizeText(&effect, msg)
+	m.Push(s)
+	shade.Run(screen, m)
+}
 
-		pos := mgl32.Vec3{
-			screen.Width / 5,
-			screen.Height/2 + h/2,
-			0}
+// Enter binds the splash screen's camera and sprites.
+func (s *Scene) Enter() {
+	s.total = 0
+	s.cam.Bind(s.screen.Program)
+	s.font.Bind(s.screen.Program)
+	s.ghost = ghost.New()
+	s.ghost.Bind(s.screen.Program)
+}
 
-		font.DrawText(pos, &effect, msg)
-		g.Draw()
+// Exit releases the splash screen's camera and sprites.
+func (s *Scene) Exit() {
+	s.cam.Release()
+	s.font.Release()
+	s.ghost.Sprite.Release()
+}
 
-		screen.Flip()
-		events.Poll()
+// HandleEvent closes the window when escape is pressed.
+func (s *Scene) HandleEvent(event events.Event) {
+	if event.Type == events.KeyUp && event.Key == glfw.KeyEscape {
+		// Send window close event
+		s.screen.Close()
+	}
+}
 
-		if total > 3000.0 {
-			running = false
+// Update the ghost, moving on to the next scene once the splash screen is done.
+func (s *Scene) Update(dt float32) {
+	s.total += dt
+	// The ghost moves in milliseconds.
+	s.ghost.Update(dt*1000, nil)
+
+	if s.total > duration {
+		if s.next != nil {
+			s.manager.Replace(s.next)
+		} else {
+			s.manager.Pop()
 		}
 	}
 }
 
+// Draw the splash screen.
+func (s *Scene) Draw(alpha float32) {
+	effect := sprite.Effects{
+		Scale:          mgl32.Vec3{1.0, 1.0, 1.0},
+		Tint:           mgl32.Vec4{1.0, 1.0, 1.0, 0.0},
+		EnableLighting: true,
+		AmbientColor:   s.ghost.AmbientColor,
+		Light:          *s.ghost.Light}
+	_, h := s.font.SizeText(&effect, msg)
+
+	pos := mgl32.Vec3{
+		s.screen.Width / 5,
+		s.screen.Height/2 + h/2,
+		0}
+
+	s.font.DrawText(pos, &effect, msg)
+	s.ghost.Draw()
+}
+
 func loadFont() (*fonts.Context, error) {
 	c, err := sprite.LoadAsset("assets/splash-font.png")
 	if err != nil {
@@ -0,0 +1,54 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// Mode an Animation plays in.
+type Mode int
+
+const (
+	// Loop plays the frames in order, starting over after the last one.
+	Loop Mode = iota
+	// Once plays the frames in order, stopping on the last one.
+	Once
+	// PingPong plays the frames forwards then backwards, over and over.
+	PingPong
+)
+
+// Animation is a sequence of frames of a sprite sheet, such as a walk cycle.  Use an
+// Animator to play it.
+type Animation struct {
+	Name   string
+	Frames []AnimationFrame
+	Mode   Mode
+}
+
+// AnimationFrame is one frame of an Animation.
+type AnimationFrame struct {
+	// Frame of the sprite sheet, as passed to DrawFrame.
+	Frame mgl32.Vec2
+	// Duration the frame is shown for in milliseconds.
+	Duration float32
+}
+
+// NewAnimation of frames which are each shown for duration milliseconds.
+func NewAnimation(name string, mode Mode, duration float32, frames ...mgl32.Vec2) *Animation {
+	a := Animation{Name: name, Mode: mode}
+	for _, f := range frames {
+		a.Frames = append(a.Frames, AnimationFrame{Frame: f, Duration: duration})
+	}
+	return &a
+}
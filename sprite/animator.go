@@ -0,0 +1,149 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// Animator plays an Animation, advancing its frames by the time passed to Update.
+type Animator struct {
+	Animation *Animation
+	// Speed playback is scaled by, 1 is normal speed and 0 pauses the animation.
+	Speed float32
+	// OnFrame is called with the index of each frame in Animation.Frames as it is
+	// shown, including the first.  It may be nil.
+	OnFrame func(a *Animator, index int)
+	// OnFinish is called when an animation played Once shows its last frame for its
+	// full duration.  It may be nil.
+	OnFinish func(a *Animator)
+	index    int
+	// elapsed time the current frame has been shown in milliseconds
+	elapsed float32
+	// reverse is true while a PingPong animation plays backwards
+	reverse bool
+	started bool
+	done    bool
+}
+
+// NewAnimator playing animation at normal speed.
+func NewAnimator(animation *Animation) *Animator {
+	return &Animator{
+		Animation: animation,
+		Speed:     1,
+	}
+}
+
+// Play animation from its first frame, unless it is already playing.
+func (a *Animator) Play(animation *Animation) {
+	if a.Animation == animation {
+		return
+	}
+	a.Animation = animation
+	a.Reset()
+}
+
+// Reset the animation to its first frame.
+func (a *Animator) Reset() {
+	a.index = 0
+	a.elapsed = 0
+	a.reverse = false
+	a.started = false
+	a.done = false
+}
+
+// Index in Animation.Frames of the frame being shown.
+func (a *Animator) Index() int {
+	return a.index
+}
+
+// Frame of the sprite sheet being shown, as passed to DrawFrame.
+func (a *Animator) Frame() mgl32.Vec2 {
+	if a.Animation == nil || len(a.Animation.Frames) == 0 {
+		return mgl32.Vec2{}
+	}
+	return a.Animation.Frames[a.index].Frame
+}
+
+// Done is true once an animation played Once has finished.  Looping animations are
+// never done.
+func (a *Animator) Done() bool {
+	return a.done
+}
+
+// Update the animation by dt seconds.  Frames passed over by a large dt are still
+// reported to OnFrame, in order.
+func (a *Animator) Update(dt float32) {
+	if a.Animation == nil || len(a.Animation.Frames) == 0 || a.done {
+		return
+	}
+	if !a.started {
+		a.started = true
+		a.frame()
+	}
+
+	var total float32
+	for _, f := range a.Animation.Frames {
+		total += f.Duration
+	}
+	if total <= 0 {
+		// Nothing would ever advance the animation.
+		return
+	}
+
+	a.elapsed += dt * 1000 * a.Speed
+	for !a.done && a.elapsed >= a.Animation.Frames[a.index].Duration {
+		a.elapsed -= a.Animation.Frames[a.index].Duration
+		a.next()
+	}
+}
+
+// next frame of the animation, depending on its mode.
+func (a *Animator) next() {
+	last := len(a.Animation.Frames) - 1
+	switch a.Animation.Mode {
+	case Once:
+		if a.index == last {
+			a.elapsed = 0
+			a.done = true
+			if a.OnFinish != nil {
+				a.OnFinish(a)
+			}
+			return
+		}
+		a.index++
+	case PingPong:
+		if last == 0 {
+			return
+		}
+		if a.reverse && a.index == 0 {
+			a.reverse = false
+		} else if !a.reverse && a.index == last {
+			a.reverse = true
+		}
+		if a.reverse {
+			a.index--
+		} else {
+			a.index++
+		}
+	default:
+		a.index = (a.index + 1) % len(a.Animation.Frames)
+	}
+	a.frame()
+}
+
+func (a *Animator) frame() {
+	if a.OnFrame != nil {
+		a.OnFrame(a, a.index)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func testAnimation(mode Mode) *Animation {
+	return &Animation{
+		Name: "test",
+		Mode: mode,
+		Frames: []AnimationFrame{
+			{Frame: mgl32.Vec2{0, 0}, Duration: 100},
+			{Frame: mgl32.Vec2{1, 0}, Duration: 200},
+			{Frame: mgl32.Vec2{2, 0}, Duration: 100},
+		},
+	}
+}
+
+func TestAnimatorModes(t *testing.T) {
+	tests := []struct {
+		mode Mode
+		// index after each 100ms update
+		want []int
+	}{
+		{Loop, []int{1, 1, 2, 0, 1}},
+		{Once, []int{1, 1, 2, 2, 2}},
+		{PingPong, []int{1, 1, 2, 1, 1, 0, 1}},
+	}
+	for _, tt := range tests {
+		a := NewAnimator(testAnimation(tt.mode))
+		for i, want := range tt.want {
+			a.Update(0.1)
+			if a.Index() != want {
+				t.Error("Expected mode", tt.mode, "update", i, "to be at index", want, "but found", a.Index())
+			}
+		}
+		if done := tt.mode == Once; a.Done() != done {
+			t.Error("Expected mode", tt.mode, "done to be", done, "but found", a.Done())
+		}
+	}
+}
+
+func TestAnimatorEvents(t *testing.T) {
+	a := NewAnimator(testAnimation(Once))
+	var frames []int
+	finished := 0
+	a.OnFrame = func(a *Animator, index int) { frames = append(frames, index) }
+	a.OnFinish = func(a *Animator) { finished++ }
+
+	// One large step passes over every frame.
+	a.Update(1)
+	a.Update(1)
+	want := []int{0, 1, 2}
+	if len(frames) != len(want) {
+		t.Fatal("Expected frames", want, "but found", frames)
+	}
+	for i := range want {
+		if frames[i] != want[i] {
+			t.Error("Expected frames", want, "but found", frames)
+			break
+		}
+	}
+	if finished != 1 {
+		t.Error("Expected to finish once but found", finished)
+	}
+	if a.Frame() != (mgl32.Vec2{2, 0}) {
+		t.Error("Expected the last frame but found", a.Frame())
+	}
+}
+
+func TestAnimatorSpeed(t *testing.T) {
+	a := NewAnimator(testAnimation(Loop))
+	a.Speed = 2
+	a.Update(0.05)
+	if a.Index() != 1 {
+		t.Error("Expected double speed index 1 but found", a.Index())
+	}
+	a.Speed = 0
+	a.Update(10)
+	if a.Index() != 1 {
+		t.Error("Expected paused index 1 but found", a.Index())
+	}
+
+	a.Play(a.Animation)
+	if a.Index() != 1 {
+		t.Error("Expected playing the same animation to continue but found index", a.Index())
+	}
+	a.Play(testAnimation(Loop))
+	if a.Index() != 0 {
+		t.Error("Expected playing another animation to restart but found index", a.Index())
+	}
+}
+
+func TestAnimatorZeroDuration(t *testing.T) {
+	a := NewAnimator(NewAnimation("zero", Loop, 0, mgl32.Vec2{0, 0}, mgl32.Vec2{1, 0}))
+	a.Update(1)
+	if a.Index() != 0 {
+		t.Error("Expected index 0 but found", a.Index())
+	}
+}
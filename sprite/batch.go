@@ -0,0 +1,237 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade/display"
+	"github.com/hurricanerix/shade/gpu"
+	"github.com/hurricanerix/shade/light"
+	"github.com/hurricanerix/shade/shader"
+)
+
+// drawCalls made by the package, for benchmarks.
+var drawCalls int
+
+// drawState is everything besides its position and frame that a sprite is drawn
+// with.  Sprites with the same state can be drawn together.
+type drawState struct {
+	program *shader.Program
+	texture uint32
+	normal  uint32
+	shadow  uint32
+	// shadowTexel, shadowHeight, shadowStrength and shadowFrame are only used with
+	// a shadow map.  Sprites with one are only drawn together when they are drawn
+	// with the same frame.
+	shadowTexel    mgl32.Vec2
+	shadowHeight   float32
+	shadowStrength float32
+	shadowFrame    mgl32.Vec4
+	colorOps
+	ambient mgl32.Vec4
+	light   light.Positional
+}
+
+// state the sprite is drawn with by p.
+func (c *Context) state(p *shader.Program) drawState {
+	s := drawState{
+		program:  p,
+		texture:  c.texLoc,
+		normal:   c.normalLoc,
+		shadow:   c.shadowLoc,
+		colorOps: c.colorOps,
+		ambient:  c.AmbientColor,
+		light:    c.Light,
+	}
+	if c.shadowLoc != 0 {
+		s.shadowTexel = c.shadowTexel
+		s.shadowHeight = c.ShadowHeight
+		if s.shadowHeight == 0 {
+			s.shadowHeight = DefaultShadowHeight
+		}
+		s.shadowStrength = c.ShadowStrength
+		if s.shadowStrength == 0 {
+			s.shadowStrength = DefaultShadowStrength
+		}
+		// Keep half a texel inside the frame, so filtering doesn't reach into the
+		// frames around it.
+		half := c.shadowTexel.Mul(0.5)
+		f := c.shadowFrame
+		s.shadowFrame = mgl32.Vec4{f[0] + half[0], f[1] + half[1], f[2] - half[0], f[3] - half[1]}
+	}
+	return s
+}
+
+// setUniforms of the state's program, which must be in use.
+func (s *drawState) setUniforms(model mgl32.Mat4, tex mgl32.Mat3) {
+	p := s.program
+	gl.Uniform1i(p.Uniform("ColorMap"), 0)
+	gl.Uniform1i(p.Uniform("NormalMap"), 1)
+	gl.Uniform1i(p.Uniform("ShadowMap"), 2)
+
+	gl.UniformMatrix4fv(p.Uniform("ModelMatrix"), 1, false, &model[0])
+	gl.UniformMatrix3fv(p.Uniform("TexMatrix"), 1, false, &tex[0])
+
+	gl.Uniform1i(p.Uniform("AddColor"), s.addColor)
+	gl.Uniform4fv(p.Uniform("AColor"), 1, &s.aColor[0])
+	gl.Uniform1i(p.Uniform("SubColor"), s.subColor)
+	gl.Uniform4fv(p.Uniform("SColor"), 1, &s.sColor[0])
+	gl.Uniform4fv(p.Uniform("MColor"), 1, &s.mColor[0])
+	gl.Uniform1i(p.Uniform("Blend"), int32(s.blend))
+
+	gl.Uniform4fv(p.Uniform("AmbientColor"), 1, &s.ambient[0])
+	gl.Uniform3fv(p.Uniform("LightPos"), 1, &s.light.Pos[0])
+	gl.Uniform4fv(p.Uniform("LightColor"), 1, &s.light.Color[0])
+	gl.Uniform1f(p.Uniform("LightPower"), s.light.Power)
+
+	useShadowMap := int32(0)
+	if s.shadow != 0 {
+		useShadowMap = 1
+	}
+	gl.Uniform1i(p.Uniform("UseShadowMap"), useShadowMap)
+	gl.Uniform2fv(p.Uniform("ShadowTexel"), 1, &s.shadowTexel[0])
+	gl.Uniform1f(p.Uniform("ShadowHeight"), s.shadowHeight)
+	gl.Uniform1f(p.Uniform("ShadowStrength"), s.shadowStrength)
+	gl.Uniform4fv(p.Uniform("ShadowFrame"), 1, &s.shadowFrame[0])
+}
+
+// Batch draws many sprites with few draw calls.  Sprites whose Batch field is set
+// to it are queued when drawn, and consecutive sprites which share textures,
+// program and effects are drawn together from one vertex buffer.  The queue is
+// drawn when that state changes, when Flush is called and in display.Context.Flip,
+// so sprites are still drawn in order.
+type Batch struct {
+	screen   *display.Context
+	vao      uint32
+	vbo      uint32
+	vertices []float32
+	state    drawState
+	// capacity of vbo in floats
+	capacity int
+}
+
+// NewBatch returns a Batch which is flushed when screen is flipped.
+func NewBatch(screen *display.Context) (*Batch, error) {
+	b := Batch{
+		screen: screen,
+	}
+
+	gl.GenVertexArrays(1, &b.vao)
+	gpu.Created(gpu.VertexArray, b.vao)
+	gl.BindVertexArray(b.vao)
+	gl.GenBuffers(1, &b.vbo)
+	gpu.Created(gpu.Buffer, b.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+
+	// Every program created by the shader package has its attributes at the same
+	// locations.
+	attribs := []struct {
+		loc    uint32
+		size   int32
+		offset int
+	}{
+		{shader.MCVertexLoc, 3, 0},
+		{shader.MCNormalLoc, 3, 3},
+		{shader.MCTangentLoc, 4, 6},
+		{shader.TexCoord0Loc, 2, 10},
+	}
+	for _, a := range attribs {
+		gl.EnableVertexAttribArray(a.loc)
+		gl.VertexAttribPointer(a.loc, a.size, gl.FLOAT, false, vertexSize*4, gl.PtrOffset(a.offset*4))
+	}
+
+	screen.AddFlusher(&b)
+	return &b, nil
+}
+
+// add a quad transformed by model with texture coordinates transformed by tex,
+// drawing what is queued first if it has a different state.
+func (b *Batch) add(state drawState, model mgl32.Mat4, tex mgl32.Mat3) {
+	if len(b.vertices) > 0 && state != b.state {
+		b.Flush()
+	}
+	b.state = state
+	b.vertices = appendQuad(b.vertices, model, tex)
+}
+
+// Flush draws the queued sprites.
+func (b *Batch) Flush() {
+	if len(b.vertices) == 0 || b.vao == 0 {
+		return
+	}
+
+	s := b.state
+	s.program.Use()
+	// The vertices are already transformed.
+	s.setUniforms(mgl32.Ident4(), mgl32.Ident3())
+
+	gl.BindVertexArray(b.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vbo)
+	if len(b.vertices) > b.capacity {
+		b.capacity = cap(b.vertices)
+		gl.BufferData(gl.ARRAY_BUFFER, b.capacity*4, nil, gl.DYNAMIC_DRAW)
+	}
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(b.vertices)*4, gl.Ptr(b.vertices))
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, s.texture)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, s.normal)
+	gl.ActiveTexture(gl.TEXTURE2)
+	gl.BindTexture(gl.TEXTURE_2D, s.shadow)
+
+	s.blend.apply()
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(b.vertices)/vertexSize))
+	drawCalls++
+	if s.blend != Alpha {
+		Alpha.apply()
+	}
+	b.vertices = b.vertices[:0]
+}
+
+// Release the batch's OpenGL objects, dropping anything queued.  Sprites must not
+// be drawn through it afterwards.
+func (b *Batch) Release() {
+	b.screen.RemoveFlusher(b)
+	gl.DeleteVertexArrays(1, &b.vao)
+	gpu.Deleted(gpu.VertexArray, b.vao)
+	gl.DeleteBuffers(1, &b.vbo)
+	gpu.Deleted(gpu.Buffer, b.vbo)
+	b.vao, b.vbo, b.capacity = 0, 0, 0
+	b.vertices = nil
+}
+
+// appendQuad of the sprite's vertices to dst, with positions and tangents transformed
+// by model and texture coordinates transformed by tex.  The tangent's handedness is
+// flipped if model mirrors the sprite, as the shader can't tell from the identity
+// model matrix batches are drawn with.
+func appendQuad(dst []float32, model mgl32.Mat4, tex mgl32.Mat3) []float32 {
+	handedness := float32(1.0)
+	if model[0]*model[5]-model[4]*model[1] < 0 {
+		handedness = -1.0
+	}
+	for i := 0; i < len(vertices); i += vertexSize {
+		v := vertices[i : i+vertexSize]
+		pos := model.Mul4x1(mgl32.Vec4{v[0], v[1], v[2], 1.0})
+		tangent := model.Mul4x1(mgl32.Vec4{v[6], v[7], v[8], 0.0})
+		st := tex.Mul3x1(mgl32.Vec3{v[10], v[11], 1.0})
+		dst = append(dst, pos[0], pos[1], pos[2])
+		dst = append(dst, v[3:6]...)
+		dst = append(dst, tangent[0], tangent[1], tangent[2], v[9]*handedness)
+		dst = append(dst, st[0], st[1])
+	}
+	return dst
+}
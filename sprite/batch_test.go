@@ -0,0 +1,144 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"image"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade/camera"
+	"github.com/hurricanerix/shade/display"
+	"github.com/hurricanerix/shade/shadetest"
+)
+
+func TestMain(m *testing.M) {
+	shadetest.Main(m)
+}
+
+func TestAppendQuad(t *testing.T) {
+	model := mgl32.Translate3D(10, 20, 3).Mul4(mgl32.Scale3D(4, 2, 0))
+	tex := mgl32.Scale2D(0.5, 1.0).Mul3(mgl32.Translate2D(1, 0))
+
+	got := appendQuad(nil, model, tex)
+
+	if len(got) != len(vertices) {
+		t.Fatalf("Expected %d floats but found %d", len(vertices), len(got))
+	}
+	// First vertex is the bottom left corner of the second frame.
+	expected := []float32{8, 19, 3, 0, 0, 1, 4, 0, 0, 1, 0.5, 1}
+	for i := range expected {
+		if !aboutTheSame(got[i], expected[i]) {
+			t.Error("Expected first vertex", expected, "but found", got[:vertexSize])
+			break
+		}
+	}
+}
+
+func TestAppendQuadFlipped(t *testing.T) {
+	model := mgl32.Scale3D(-4, 2, 1)
+	got := appendQuad(nil, model, mgl32.Ident3())
+
+	// The tangent points left and the bitangent is mirrored.
+	expected := []float32{2, -1, 0, 0, 0, 1, -4, 0, 0, -1, 0, 1}
+	for i := range expected {
+		if !aboutTheSame(got[i], expected[i]) {
+			t.Error("Expected first vertex", expected, "but found", got[:vertexSize])
+			break
+		}
+	}
+}
+
+func aboutTheSame(a, b float32) bool {
+	d := a - b
+	return d < 0.0001 && d > -0.0001
+}
+
+// Size of the benchmark's tile map and how many characters of text are drawn over
+// it.
+const (
+	mapWidth  = 60
+	mapHeight = 15
+	textSize  = 200
+)
+
+type tileMapScene struct {
+	batched bool
+	tiles   *Context
+	font    *Context
+}
+
+func (s *tileMapScene) Setup(screen *display.Context, cam *camera.Context) error {
+	var err error
+	if s.tiles, err = New(image.NewRGBA(image.Rect(0, 0, 32, 16)), nil, 2, 1); err != nil {
+		return err
+	}
+	if s.font, err = New(image.NewRGBA(image.Rect(0, 0, 256, 24)), nil, 32, 3); err != nil {
+		return err
+	}
+	s.tiles.Bind(screen.Program)
+	s.font.Bind(screen.Program)
+	if s.batched {
+		b, err := NewBatch(screen)
+		if err != nil {
+			return err
+		}
+		s.tiles.Batch = b
+		s.font.Batch = b
+	}
+	return nil
+}
+
+func (s *tileMapScene) Frame(n int, dt float32) {
+	for y := 0; y < mapHeight; y++ {
+		for x := 0; x < mapWidth; x++ {
+			frame := mgl32.Vec2{float32((x + y) % 2), 0}
+			s.tiles.DrawFrame(frame, mgl32.Vec3{float32(x * 16), float32(y * 16), 0}, nil)
+		}
+	}
+	for i := 0; i < textSize; i++ {
+		frame := mgl32.Vec2{float32(i % 32), float32(i % 3)}
+		s.font.DrawFrame(frame, mgl32.Vec3{float32(i%80) * 8, float32(i/80) * 8, 1}, nil)
+	}
+}
+
+func (s *tileMapScene) release() {
+	if s.tiles.Batch != nil {
+		s.tiles.Batch.Release()
+	}
+	s.tiles.Release()
+	s.font.Release()
+}
+
+func benchmarkTileMap(b *testing.B, batched bool) {
+	s := tileMapScene{batched: batched}
+	drawCalls = 0
+	_, err := shadetest.Render(&s, shadetest.Options{Frames: b.N})
+	if err != nil {
+		b.Skip(err)
+	}
+	b.ReportMetric(float64(drawCalls)/float64(b.N), "draws/frame")
+	shadetest.Do(s.release)
+}
+
+// BenchmarkTileMap draws every sprite with its own draw call.
+func BenchmarkTileMap(b *testing.B) {
+	benchmarkTileMap(b, false)
+}
+
+// BenchmarkTileMapBatched draws the map and the text with a draw call each.
+func BenchmarkTileMapBatched(b *testing.B) {
+	benchmarkTileMap(b, true)
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// BlendMode a sprite is drawn over what is behind it with.
+type BlendMode int
+
+const (
+	// Alpha blends by the sprite's alpha, the default.
+	Alpha BlendMode = iota
+	// Additive adds the sprite's color, brightening what is behind it, for glows
+	// and sparks.
+	Additive
+	// Multiply multiplies what is behind the sprite by its color, darkening it, for
+	// shade and stains.
+	Multiply
+	// Screen is the inverse of Multiply, lightening what is behind the sprite
+	// without ever going over white.
+	Screen
+	// Premultiplied blends sprites whose color is already multiplied by their
+	// alpha, which avoids dark fringes around smoothly scaled sprites.
+	Premultiplied
+)
+
+// factors of gl.BlendFunc for the mode.
+func (m BlendMode) factors() (src, dst uint32) {
+	switch m {
+	case Additive:
+		return gl.SRC_ALPHA, gl.ONE
+	case Multiply:
+		// The shader fades the color towards white by alpha.
+		return gl.DST_COLOR, gl.ZERO
+	case Screen:
+		// The shader multiplies the color by alpha.
+		return gl.ONE, gl.ONE_MINUS_SRC_COLOR
+	case Premultiplied:
+		return gl.ONE, gl.ONE_MINUS_SRC_ALPHA
+	default:
+		return gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA
+	}
+}
+
+// apply the mode to OpenGL's blend function.
+func (m BlendMode) apply() {
+	gl.BlendFunc(m.factors())
+}
+
+// colorOps of effects e, as the uniforms of the shaders' color operations.
+type colorOps struct {
+	addColor int32
+	aColor   mgl32.Vec4
+	subColor int32
+	sColor   mgl32.Vec4
+	mColor   mgl32.Vec4
+	blend    BlendMode
+}
+
+func (e *Effects) colorOps() colorOps {
+	ops := colorOps{
+		addColor: 1,
+		aColor:   e.Tint,
+		sColor:   e.Subtract,
+		mColor:   e.Modulate,
+		blend:    e.Blend,
+	}
+	if e.Subtract != (mgl32.Vec4{}) {
+		ops.subColor = 1
+	}
+	if ops.mColor == (mgl32.Vec4{}) {
+		ops.mColor = mgl32.Vec4{1, 1, 1, 1}
+	}
+	opacity := 1 - e.Fade
+	if opacity < 0 {
+		opacity = 0
+	}
+	ops.mColor[3] *= opacity
+	if e.Blend == Premultiplied {
+		// The color must stay multiplied by alpha.
+		for i := 0; i < 3; i++ {
+			ops.mColor[i] *= ops.mColor[3]
+		}
+	}
+	return ops
+}
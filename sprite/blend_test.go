@@ -0,0 +1,141 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestColorOps(t *testing.T) {
+	tests := []struct {
+		name     string
+		e        Effects
+		subColor int32
+		mColor   mgl32.Vec4
+	}{
+		{"default", Effects{}, 0, mgl32.Vec4{1, 1, 1, 1}},
+		{"subtract", Effects{Subtract: mgl32.Vec4{0.5, 0, 0, 0}}, 1, mgl32.Vec4{1, 1, 1, 1}},
+		{"modulate", Effects{Modulate: mgl32.Vec4{1, 0.5, 0, 1}}, 0, mgl32.Vec4{1, 0.5, 0, 1}},
+		{"fade", Effects{Fade: 0.25}, 0, mgl32.Vec4{1, 1, 1, 0.75}},
+		{"faded out", Effects{Fade: 2}, 0, mgl32.Vec4{1, 1, 1, 0}},
+		{"premultiplied fade", Effects{Fade: 0.5, Blend: Premultiplied}, 0, mgl32.Vec4{0.5, 0.5, 0.5, 0.5}},
+	}
+	for _, tt := range tests {
+		ops := tt.e.colorOps()
+		if ops.addColor != 1 || ops.subColor != tt.subColor || ops.mColor != tt.mColor {
+			t.Errorf("%s: ops were %+v, expected subColor %d and mColor %v", tt.name, ops, tt.subColor, tt.mColor)
+		}
+		if ops.blend != tt.e.Blend {
+			t.Errorf("%s: blend was %d, expected %d", tt.name, ops.blend, tt.e.Blend)
+		}
+	}
+}
+
+func TestBlendFactors(t *testing.T) {
+	tests := []struct {
+		mode     BlendMode
+		src, dst uint32
+	}{
+		{Alpha, gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA},
+		{Additive, gl.SRC_ALPHA, gl.ONE},
+		{Multiply, gl.DST_COLOR, gl.ZERO},
+		{Screen, gl.ONE, gl.ONE_MINUS_SRC_COLOR},
+		{Premultiplied, gl.ONE, gl.ONE_MINUS_SRC_ALPHA},
+	}
+	for _, tt := range tests {
+		if src, dst := tt.mode.factors(); src != tt.src || dst != tt.dst {
+			t.Errorf("mode %d factors were %d %d, expected %d %d", tt.mode, src, dst, tt.src, tt.dst)
+		}
+	}
+}
+
+// shade the sprite's color c and alpha a as the fragment shader does for mode m.
+func shade(m BlendMode, c mgl32.Vec3, a float32) mgl32.Vec3 {
+	switch m {
+	case Multiply:
+		return mgl32.Vec3{1, 1, 1}.Mul(1 - a).Add(c.Mul(a))
+	case Screen:
+		return c.Mul(a)
+	}
+	return c
+}
+
+// factor evaluates the OpenGL blend factor f for source s with alpha a over
+// destination d.
+func factor(f uint32, s mgl32.Vec3, a float32, d mgl32.Vec3) mgl32.Vec3 {
+	switch f {
+	case gl.ZERO:
+		return mgl32.Vec3{}
+	case gl.ONE:
+		return mgl32.Vec3{1, 1, 1}
+	case gl.SRC_ALPHA:
+		return mgl32.Vec3{a, a, a}
+	case gl.ONE_MINUS_SRC_ALPHA:
+		return mgl32.Vec3{1 - a, 1 - a, 1 - a}
+	case gl.SRC_COLOR:
+		return s
+	case gl.ONE_MINUS_SRC_COLOR:
+		return mgl32.Vec3{1 - s[0], 1 - s[1], 1 - s[2]}
+	case gl.DST_COLOR:
+		return d
+	}
+	panic("unexpected blend factor")
+}
+
+// blend src with alpha a over dst as OpenGL does with the mode's factors.
+func blend(m BlendMode, src mgl32.Vec3, a float32, dst mgl32.Vec3) mgl32.Vec3 {
+	s := shade(m, src, a)
+	sf, df := m.factors()
+	fs, fd := factor(sf, s, a, dst), factor(df, s, a, dst)
+	var out mgl32.Vec3
+	for i := range out {
+		out[i] = s[i]*fs[i] + dst[i]*fd[i]
+	}
+	return out
+}
+
+func TestBlendModes(t *testing.T) {
+	src := mgl32.Vec3{0.5, 0.2, 0.8}
+	dst := mgl32.Vec3{0.4, 0.6, 1.0}
+	// What each mode claims to do with the sprite's color s and alpha a over d.
+	claims := []struct {
+		mode BlendMode
+		f    func(s, d, a float32) float32
+	}{
+		{Alpha, func(s, d, a float32) float32 { return s*a + d*(1-a) }},
+		{Additive, func(s, d, a float32) float32 { return d + s*a }},
+		{Multiply, func(s, d, a float32) float32 { return d * (1 - a + s*a) }},
+		{Screen, func(s, d, a float32) float32 { return 1 - (1-d)*(1-s*a) }},
+		// The sprite's color is already multiplied by alpha.
+		{Premultiplied, func(s, d, a float32) float32 { return s + d*(1-a) }},
+	}
+	for _, c := range claims {
+		for _, a := range []float32{0, 0.5, 1} {
+			s := src
+			if c.mode == Premultiplied {
+				s = src.Mul(a)
+			}
+			got := blend(c.mode, s, a, dst)
+			for i := range got {
+				if expected := c.f(s[i], dst[i], a); !aboutTheSame(got[i], expected) {
+					t.Error("Expected mode", c.mode, "with alpha", a, "to blend to", expected, "but found", got[i])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Op compares a Controller parameter to a Condition's value.
+type Op int
+
+const (
+	// Equal is true if the parameter equals the value.
+	Equal Op = iota
+	// NotEqual is true if the parameter does not equal the value.
+	NotEqual
+	// Greater is true if the parameter is greater than the value.
+	Greater
+	// Less is true if the parameter is less than the value.
+	Less
+)
+
+// Condition on a Controller parameter, which must be true for a Transition.
+type Condition struct {
+	Param string
+	Op    Op
+	Value float32
+}
+
+// If the bool parameter is true.
+func If(param string) Condition {
+	return Condition{Param: param, Op: NotEqual, Value: 0}
+}
+
+// IfNot the bool parameter is true.
+func IfNot(param string) Condition {
+	return Condition{Param: param, Op: Equal, Value: 0}
+}
+
+// IfGreater if the parameter is greater than value.
+func IfGreater(param string, value float32) Condition {
+	return Condition{Param: param, Op: Greater, Value: value}
+}
+
+// IfLess if the parameter is less than value.
+func IfLess(param string, value float32) Condition {
+	return Condition{Param: param, Op: Less, Value: value}
+}
+
+func (c Condition) test(params map[string]float32) bool {
+	v := params[c.Param]
+	switch c.Op {
+	case NotEqual:
+		return v != c.Value
+	case Greater:
+		return v > c.Value
+	case Less:
+		return v < c.Value
+	default:
+		return v == c.Value
+	}
+}
+
+// Transition from one state of a Controller to another.
+type Transition struct {
+	// To is the name of the state transitioned to.
+	To string
+	// Conditions which must all be true for the transition.
+	Conditions []Condition
+	// HasExitTime makes the transition wait until the state's animation has played
+	// for ExitTime, as a fraction of its total duration.  1 waits for it to play
+	// through once, 0.5 for half of it, and so on.
+	HasExitTime bool
+	ExitTime    float32
+}
+
+// After sets the transition to wait for exitTime, see HasExitTime.
+func (t *Transition) After(exitTime float32) *Transition {
+	t.HasExitTime = true
+	t.ExitTime = exitTime
+	return t
+}
+
+// State of a Controller, which plays its Animation until one of its Transitions
+// is taken.
+type State struct {
+	Name        string
+	Animation   *Animation
+	Transitions []*Transition
+}
+
+// To adds a transition to the state called name, when all the conditions are true.
+func (s *State) To(name string, conditions ...Condition) *Transition {
+	t := Transition{To: name, Conditions: conditions}
+	s.Transitions = append(s.Transitions, &t)
+	return &t
+}
+
+// Controller chooses which Animation an Animator plays, from states which are
+// transitioned between as its parameters change.  Parameters are set by the game
+// each update, bool parameters are stored as 0 or 1.
+type Controller struct {
+	Animator *Animator
+	// OnChange is called after the state changes.  It may be nil.
+	OnChange func(c *Controller, from, to string)
+	states   map[string]*State
+	current  *State
+	params   map[string]float32
+	// elapsed time in the current state in milliseconds
+	elapsed float32
+}
+
+// NewController without any states, see AddState.
+func NewController() *Controller {
+	return &Controller{
+		Animator: NewAnimator(nil),
+		states:   map[string]*State{},
+		params:   map[string]float32{},
+	}
+}
+
+// AddState called name, which plays animation.
+func (c *Controller) AddState(name string, animation *Animation) *State {
+	s := State{Name: name, Animation: animation}
+	c.states[name] = &s
+	return &s
+}
+
+// Start the controller in the state called name.  It is an error for any
+// transition to lead to a state which was not added.
+func (c *Controller) Start(name string) error {
+	for _, s := range c.states {
+		for _, t := range s.Transitions {
+			if _, ok := c.states[t.To]; !ok {
+				return fmt.Errorf("state %s transitions to unknown state %s", s.Name, t.To)
+			}
+		}
+	}
+	s, ok := c.states[name]
+	if !ok {
+		return fmt.Errorf("unknown state %s", name)
+	}
+	c.enter(s)
+	return nil
+}
+
+// State is the name of the current state, or "" before Start is called.
+func (c *Controller) State() string {
+	if c.current == nil {
+		return ""
+	}
+	return c.current.Name
+}
+
+// SetBool parameter name to v.
+func (c *Controller) SetBool(name string, v bool) {
+	if v {
+		c.params[name] = 1
+	} else {
+		c.params[name] = 0
+	}
+}
+
+// SetFloat parameter name to v.
+func (c *Controller) SetFloat(name string, v float32) {
+	c.params[name] = v
+}
+
+// Bool value of parameter name, false if it was never set.
+func (c *Controller) Bool(name string) bool {
+	return c.params[name] != 0
+}
+
+// Float value of parameter name, 0 if it was never set.
+func (c *Controller) Float(name string) float32 {
+	return c.params[name]
+}
+
+// Frame of the sprite sheet being shown, as passed to DrawFrame.
+func (c *Controller) Frame() mgl32.Vec2 {
+	return c.Animator.Frame()
+}
+
+// Update the animation by dt seconds, then take the first transition of the current
+// state whose conditions are true, in the order they were added.
+func (c *Controller) Update(dt float32) {
+	if c.current == nil {
+		return
+	}
+	c.Animator.Update(dt)
+	c.elapsed += dt * 1000 * c.Animator.Speed
+
+	for _, t := range c.current.Transitions {
+		if c.ready(t) {
+			from := c.current.Name
+			c.enter(c.states[t.To])
+			if c.OnChange != nil {
+				c.OnChange(c, from, t.To)
+			}
+			return
+		}
+	}
+}
+
+// ready is true if t can be taken from the current state.
+func (c *Controller) ready(t *Transition) bool {
+	for _, cond := range t.Conditions {
+		if !cond.test(c.params) {
+			return false
+		}
+	}
+	if !t.HasExitTime {
+		return true
+	}
+	if c.Animator.Done() {
+		return true
+	}
+	var total float32
+	if a := c.current.Animation; a != nil {
+		for _, f := range a.Frames {
+			total += f.Duration
+		}
+	}
+	return c.elapsed >= t.ExitTime*total
+}
+
+func (c *Controller) enter(s *State) {
+	c.current = s
+	c.elapsed = 0
+	c.Animator.Animation = s.Animation
+	c.Animator.Reset()
+}
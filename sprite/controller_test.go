@@ -0,0 +1,104 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func testController(t *testing.T) *Controller {
+	c := NewController()
+	idle := c.AddState("idle", NewAnimation("idle", Loop, 100, mgl32.Vec2{0, 0}))
+	walk := c.AddState("walk", NewAnimation("walk", Loop, 100, mgl32.Vec2{1, 0}, mgl32.Vec2{2, 0}))
+	jump := c.AddState("jump", NewAnimation("jump", Once, 100, mgl32.Vec2{3, 0}))
+	fall := c.AddState("fall", NewAnimation("fall", Loop, 100, mgl32.Vec2{4, 0}))
+
+	idle.To("jump", IfNot("Resting"), IfGreater("dy", 0))
+	idle.To("walk", If("Walking"))
+	walk.To("jump", IfNot("Resting"), IfGreater("dy", 0))
+	// Finish the step before stopping.
+	walk.To("idle", IfNot("Walking")).After(1)
+	jump.To("fall", IfLess("dy", 0))
+	fall.To("idle", If("Resting"))
+
+	if err := c.Start("idle"); err != nil {
+		t.Fatal(err)
+	}
+	c.SetBool("Resting", true)
+	return c
+}
+
+func TestController(t *testing.T) {
+	c := testController(t)
+
+	tests := []struct {
+		walking bool
+		resting bool
+		dy      float32
+		state   string
+		frame   mgl32.Vec2
+	}{
+		{false, true, 0, "idle", mgl32.Vec2{0, 0}},
+		{true, true, 0, "walk", mgl32.Vec2{1, 0}},
+		// Stopping waits for the walk cycle to finish.
+		{false, true, 0, "walk", mgl32.Vec2{2, 0}},
+		{false, true, 0, "idle", mgl32.Vec2{0, 0}},
+		{false, false, 10, "jump", mgl32.Vec2{3, 0}},
+		{false, false, -10, "fall", mgl32.Vec2{4, 0}},
+		{false, true, 0, "idle", mgl32.Vec2{0, 0}},
+	}
+	for i, tt := range tests {
+		c.SetBool("Walking", tt.walking)
+		c.SetBool("Resting", tt.resting)
+		c.SetFloat("dy", tt.dy)
+		c.Update(0.1)
+		if c.State() != tt.state {
+			t.Error("Expected update", i, "to be in state", tt.state, "but found", c.State())
+		}
+		if c.Frame() != tt.frame {
+			t.Error("Expected update", i, "to show frame", tt.frame, "but found", c.Frame())
+		}
+	}
+}
+
+func TestControllerOnChange(t *testing.T) {
+	c := testController(t)
+	var changes []string
+	c.OnChange = func(c *Controller, from, to string) {
+		changes = append(changes, from+">"+to)
+	}
+	c.SetBool("Walking", true)
+	c.Update(0.01)
+	c.Update(0.01)
+	if len(changes) != 1 || changes[0] != "idle>walk" {
+		t.Error("Expected changes [idle>walk] but found", changes)
+	}
+}
+
+func TestControllerStartErrors(t *testing.T) {
+	c := NewController()
+	c.AddState("idle", nil).To("missing")
+	if err := c.Start("idle"); err == nil {
+		t.Error("Expected an error for a transition to an unknown state but found nil")
+	}
+
+	c = NewController()
+	c.AddState("idle", nil)
+	if err := c.Start("walk"); err == nil {
+		t.Error("Expected an error for an unknown start state but found nil")
+	}
+}
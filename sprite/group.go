@@ -13,3 +13,205 @@
 // limitations under the License.
 
 package sprite
+
+import (
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade/entity"
+)
+
+// Updater is updated by a Group it belongs to, which is passed along so it can
+// collide with the other members, see Group.Collide.
+type Updater interface {
+	Update(dt float32, group *Group)
+}
+
+// Group of entities, modeled on PyGame's sprite groups.  An entity is only in a
+// group once, and the group keeps the order they were added in.
+type Group struct {
+	entities []entity.Entity
+}
+
+// NewGroup containing entities.
+func NewGroup(entities ...entity.Entity) *Group {
+	g := Group{}
+	g.Add(entities...)
+	return &g
+}
+
+// Add entities which are not already in the group.
+func (g *Group) Add(entities ...entity.Entity) {
+	for _, e := range entities {
+		if !g.Has(e) {
+			g.entities = append(g.entities, e)
+		}
+	}
+}
+
+// Remove entities from the group, ignoring those which are not in it.
+func (g *Group) Remove(entities ...entity.Entity) {
+	for _, e := range entities {
+		for i := range g.entities {
+			if g.entities[i] == e {
+				g.entities = append(g.entities[:i], g.entities[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Has returns true if all of the entities are in the group.
+func (g *Group) Has(entities ...entity.Entity) bool {
+	for _, e := range entities {
+		found := false
+		for i := range g.entities {
+			if g.entities[i] == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Empty removes every entity from the group, like PyGame's Group.empty.
+func (g *Group) Empty() {
+	g.entities = nil
+}
+
+// Len is the number of entities in the group.
+func (g *Group) Len() int {
+	return len(g.entities)
+}
+
+// Entities in the group, in the order they were added.  The slice is a copy, so the
+// group may be changed while ranging over it.
+func (g *Group) Entities() []entity.Entity {
+	return append([]entity.Entity(nil), g.entities...)
+}
+
+// Update every entity in the group which is an Updater or an entity.Updater by dt.
+// Entities added during the update are not updated until the next one.
+func (g *Group) Update(dt float32) {
+	for _, e := range g.Entities() {
+		switch u := e.(type) {
+		case Updater:
+			u.Update(dt, g)
+		case entity.Updater:
+			u.Update(dt, &g.entities)
+		}
+	}
+}
+
+// Draw every entity in the group which is an entity.Drawer, layered by Z, see
+// entity.Draw.
+func (g *Group) Draw() {
+	entity.Draw(g.entities)
+}
+
+// positioned entities have a Pos, which both entity.Drawer and entity.Collider do.
+type positioned interface {
+	Pos() mgl32.Vec3
+}
+
+// Layers of the group, which are the Zs of its entities from the bottom up.
+func (g *Group) Layers() []float32 {
+	var layers []float32
+	seen := map[float32]bool{}
+	for _, e := range g.entities {
+		if p, ok := e.(positioned); ok {
+			z := p.Pos()[2]
+			if !seen[z] {
+				seen[z] = true
+				layers = append(layers, z)
+			}
+		}
+	}
+	sort.Sort(byValue(layers))
+	return layers
+}
+
+// Layer returns the entities of the group at Z z.
+func (g *Group) Layer(z float32) []entity.Entity {
+	var layer []entity.Entity
+	for _, e := range g.entities {
+		if p, ok := e.(positioned); ok && p.Pos()[2] == z {
+			layer = append(layer, e)
+		}
+	}
+	return layer
+}
+
+type byValue []float32
+
+func (s byValue) Len() int           { return len(s) }
+func (s byValue) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byValue) Less(i, j int) bool { return s[i] < s[j] }
+
+// Colliders in the group.
+func (g *Group) Colliders() []entity.Collider {
+	var colliders []entity.Collider
+	for _, e := range g.entities {
+		if c, ok := e.(entity.Collider); ok {
+			colliders = append(colliders, c)
+		}
+	}
+	return colliders
+}
+
+// Collide target with the colliders in the group, like PyGame's spritecollide.  If
+// kill is true the hits are removed from the group.  target never hits itself.
+func (g *Group) Collide(target entity.Collider, kill bool) []entity.Collision {
+	colliders := g.Colliders()
+	hits := entity.Collide(target, &colliders, false)
+	if kill {
+		for _, h := range hits {
+			g.Remove(h.Hit)
+		}
+	}
+	return hits
+}
+
+// CollideAny returns a collider in the group which target hits, or nil if there
+// are none, like PyGame's spritecollideany.
+func (g *Group) CollideAny(target entity.Collider) entity.Collider {
+	colliders := g.Colliders()
+	for i := range colliders {
+		if colliders[i] == target {
+			continue
+		}
+		group := colliders[i : i+1]
+		if len(entity.Collide(target, &group, false)) > 0 {
+			return colliders[i]
+		}
+	}
+	return nil
+}
+
+// GroupCollide every collider in a with those in b, like PyGame's groupcollide.  The
+// hits of each collider in a which hit anything are returned by it.  If killA or
+// killB are true the colliders of that group which hit anything are removed from it.
+func GroupCollide(a, b *Group, killA, killB bool) map[entity.Collider][]entity.Collision {
+	hits := map[entity.Collider][]entity.Collision{}
+	targets := b.Colliders()
+	for _, c := range a.Colliders() {
+		if h := entity.Collide(c, &targets, false); len(h) > 0 {
+			hits[c] = h
+		}
+	}
+	for c, h := range hits {
+		if killA {
+			a.Remove(c)
+		}
+		if killB {
+			for _, hit := range h {
+				b.Remove(hit.Hit)
+			}
+		}
+	}
+	return hits
+}
@@ -0,0 +1,143 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade/entity"
+	"github.com/hurricanerix/shade/shapes"
+)
+
+type box struct {
+	pos     mgl32.Vec3
+	updated int
+}
+
+func (b *box) Pos() mgl32.Vec3 {
+	return b.pos
+}
+
+func (b *box) Bounds() shapes.Shape {
+	return *shapes.NewRect(0, 10, 0, 10)
+}
+
+func (b *box) Update(dt float32, group *Group) {
+	b.updated++
+}
+
+func TestGroupMembership(t *testing.T) {
+	a, b, c := &box{}, &box{}, &box{}
+	g := NewGroup(a, b, a)
+
+	if g.Len() != 2 {
+		t.Error("Expected Len() 2 but found", g.Len())
+	}
+	if !g.Has(a, b) {
+		t.Error("Expected Has(a, b) but found false")
+	}
+	if g.Has(a, c) {
+		t.Error("Expected not Has(a, c) but found true")
+	}
+
+	g.Remove(a, c)
+	if g.Has(a) || !g.Has(b) {
+		t.Error("Expected Remove(a, c) to leave only b but found", g.Entities())
+	}
+
+	g.Empty()
+	if g.Len() != 0 {
+		t.Error("Expected Len() 0 after Empty() but found", g.Len())
+	}
+}
+
+func TestGroupUpdate(t *testing.T) {
+	a, b := &box{}, &box{}
+	g := NewGroup(a, b, "not an updater")
+
+	g.Update(1)
+	g.Remove(b)
+	g.Update(1)
+
+	if a.updated != 2 || b.updated != 1 {
+		t.Error("Expected updates 2 and 1 but found", a.updated, "and", b.updated)
+	}
+}
+
+func TestGroupLayers(t *testing.T) {
+	a := &box{pos: mgl32.Vec3{0, 0, 2}}
+	b := &box{pos: mgl32.Vec3{0, 0, -1}}
+	c := &box{pos: mgl32.Vec3{0, 0, 2}}
+	g := NewGroup(a, b, c)
+
+	layers := g.Layers()
+	if len(layers) != 2 || layers[0] != -1 || layers[1] != 2 {
+		t.Error("Expected Layers() [-1 2] but found", layers)
+	}
+	if l := g.Layer(2); len(l) != 2 || l[0] != a || l[1] != c {
+		t.Error("Expected Layer(2) [a c] but found", l)
+	}
+}
+
+func TestGroupCollide(t *testing.T) {
+	tests := []struct {
+		kill     bool
+		hits     int
+		expected int
+	}{
+		{false, 2, 3},
+		{true, 2, 1},
+	}
+
+	for i, tc := range tests {
+		target := &box{pos: mgl32.Vec3{0, 0, 0}}
+		g := NewGroup(
+			target,
+			&box{pos: mgl32.Vec3{5, 0, 0}},
+			&box{pos: mgl32.Vec3{0, 5, 0}},
+			&box{pos: mgl32.Vec3{50, 50, 0}},
+		)
+
+		hits := g.Collide(target, tc.kill)
+
+		if len(hits) != tc.hits {
+			t.Error(i, "Expected", tc.hits, "hits but found", len(hits))
+		}
+		if g.Len() != tc.expected+1 {
+			t.Error(i, "Expected", tc.expected+1, "entities left but found", g.Len())
+		}
+		if hit := g.CollideAny(target); (hit != nil) == tc.kill {
+			t.Error(i, "Expected CollideAny to hit", !tc.kill, "but found", hit)
+		}
+	}
+}
+
+func TestGroupCollideGroups(t *testing.T) {
+	a1 := &box{pos: mgl32.Vec3{0, 0, 0}}
+	a2 := &box{pos: mgl32.Vec3{100, 100, 0}}
+	b1 := &box{pos: mgl32.Vec3{5, 5, 0}}
+	b2 := &box{pos: mgl32.Vec3{-100, 0, 0}}
+	a, b := NewGroup(a1, a2), NewGroup(b1, b2)
+
+	hits := GroupCollide(a, b, true, true)
+
+	if len(hits) != 1 || len(hits[a1]) != 1 || hits[a1][0].Hit != entity.Collider(b1) {
+		t.Error("Expected a1 to hit b1 but found", hits)
+	}
+	if !a.Has(a2) || a.Has(a1) || !b.Has(b2) || b.Has(b1) {
+		t.Error("Expected a1 and b1 to be removed but found", a.Entities(), "and", b.Entities())
+	}
+}
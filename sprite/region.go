@@ -0,0 +1,78 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/hurricanerix/shade/shader"
+)
+
+// Region returns a sprite of framesX by framesY frames in the rectangle r of the
+// sprite's maps, in pixels.  Regions share the textures of the sprite they are made
+// from, called their page, so regions of the same page can be drawn together by a
+// Batch.  Binding a region binds its page if it is not already bound.
+func (c *Context) Region(r image.Rectangle, framesX, framesY int) (*Context, error) {
+	m := c.ColorMap
+	if m == nil {
+		m = c.NormalMap
+	}
+	if m == nil {
+		return nil, fmt.Errorf("sprite has no maps to take a region of")
+	}
+	b := m.Bounds()
+	if !r.In(b) || r.Empty() {
+		return nil, fmt.Errorf("region %v is not inside the sprite's bounds %v", r, b)
+	}
+	if framesX < 1 || framesY < 1 {
+		return nil, fmt.Errorf("region must have at least one frame, not %dx%d", framesX, framesY)
+	}
+
+	size := b.Size()
+	return &Context{
+		Width:        r.Dx() / framesX,
+		Height:       r.Dy() / framesY,
+		framesX:      framesX,
+		framesY:      framesY,
+		AmbientColor: c.AmbientColor,
+		colorOps:     c.colorOps,
+		page:         c,
+		region: mgl32.Vec4{
+			float32(r.Min.X-b.Min.X) / float32(size.X),
+			float32(r.Min.Y-b.Min.Y) / float32(size.Y),
+			float32(r.Dx()) / float32(size.X),
+			float32(r.Dy()) / float32(size.Y),
+		},
+	}, nil
+}
+
+// bindRegion to program, sharing its page's OpenGL objects.
+func (c *Context) bindRegion(program uint32) error {
+	if c.page.vao == 0 {
+		if err := c.page.Bind(program); err != nil {
+			return err
+		}
+	}
+	c.texLoc, c.normalLoc = c.page.texLoc, c.page.normalLoc
+	c.shadowLoc, c.shadowTexel, c.ShadowHeight = c.page.shadowLoc, c.page.shadowTexel, c.page.ShadowHeight
+	c.ShadowStrength = c.page.ShadowStrength
+	c.vao, c.vbo = c.page.vao, c.page.vbo
+	c.program = shader.Lookup(program)
+	c.program.Use()
+	c.setUniforms(c.program)
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"image"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestRegion(t *testing.T) {
+	page, err := New(image.NewRGBA(image.Rect(0, 0, 64, 32)), nil, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := page.Region(image.Rect(16, 8, 48, 16), 4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Width != 8 || r.Height != 8 {
+		t.Error("Expected 8x8 frames but found", r.Width, r.Height)
+	}
+	expected := mgl32.Vec4{0.25, 0.25, 0.5, 0.25}
+	if r.region != expected {
+		t.Error("Expected region", expected, "but found", r.region)
+	}
+
+	if _, err := page.Region(image.Rect(60, 0, 70, 10), 1, 1); err == nil {
+		t.Error("Expected an error for a region outside the page")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// Filter used to sample a sprite's textures when they are scaled.
+type Filter int
+
+const (
+	// Nearest texel is used, keeping pixel art sharp.
+	Nearest Filter = iota
+	// Linear blends the nearest texels, for smoothly scaled art.
+	Linear
+)
+
+// Wrap of texture coordinates outside of a sprite's textures.
+type Wrap int
+
+const (
+	// Repeat the texture.
+	Repeat Wrap = iota
+	// Clamp to the texels at the edge of the texture.
+	Clamp
+	// Mirror the texture each time it repeats.
+	Mirror
+)
+
+// Sampling of a sprite's textures.  The zero value samples the nearest texel of a
+// single level, repeating the texture, which suits pixel art drawn at whole scales.
+type Sampling struct {
+	Filter Filter
+	Wrap   Wrap
+	// Mipmaps are generated when the textures are bound, so art drawn smaller
+	// than its size does not shimmer.  Each level averages twice as many texels as
+	// the one before, so the frames of a sheet or regions of an atlas bleed into
+	// each other unless MipLevels is limited to what the space between them keeps
+	// apart.
+	Mipmaps bool
+	// MipLevels limits mipmaps to that many levels smaller than the texture, or
+	// every level if 0.  Frames padded by p pixels don't bleed for log2(p) levels.
+	MipLevels int
+	// Anisotropy is the maximum anisotropic filtering used, which keeps stretched
+	// sprites sharp.  It is limited to what the driver supports, and ignored
+	// without the EXT_texture_filter_anisotropic extension.  0 or 1 disables it.
+	Anisotropy float32
+}
+
+// Defined by EXT_texture_filter_anisotropic, which is not part of OpenGL 4.1 core.
+const (
+	textureMaxAnisotropy    = 0x84FE
+	maxTextureMaxAnisotropy = 0x84FF
+)
+
+// maxAnisotropy supported by the driver, 1 if it is not supported and 0 until it
+// is first queried.
+var maxAnisotropy float32
+
+// apply the sampling parameters to the bound texture, before its image is uploaded.
+func (s Sampling) apply() {
+	filter := int32(gl.NEAREST)
+	minFilter := int32(gl.NEAREST)
+	if s.Filter == Linear {
+		filter = gl.LINEAR
+		minFilter = gl.LINEAR
+	}
+	maxLevel := int32(0)
+	if s.Mipmaps {
+		// Let OpenGL use every level GenerateMipmap creates.
+		maxLevel = 1000
+		if s.MipLevels > 0 {
+			maxLevel = int32(s.MipLevels)
+		}
+		minFilter = gl.NEAREST_MIPMAP_NEAREST
+		if s.Filter == Linear {
+			minFilter = gl.LINEAR_MIPMAP_LINEAR
+		}
+	}
+	wrap := int32(gl.REPEAT)
+	switch s.Wrap {
+	case Clamp:
+		wrap = gl.CLAMP_TO_EDGE
+	case Mirror:
+		wrap = gl.MIRRORED_REPEAT
+	}
+
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, 0)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, maxLevel)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, minFilter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, filter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, wrap)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, wrap)
+
+	if maxAnisotropy == 0 {
+		maxAnisotropy = 1
+		if glfw.ExtensionSupported("GL_EXT_texture_filter_anisotropic") {
+			gl.GetFloatv(maxTextureMaxAnisotropy, &maxAnisotropy)
+		}
+	}
+	if maxAnisotropy > 1 {
+		// Also resets textures bound again with it disabled.
+		gl.TexParameterf(gl.TEXTURE_2D, textureMaxAnisotropy, s.anisotropy(maxAnisotropy))
+	}
+}
+
+// anisotropy to use, limited to max.
+func (s Sampling) anisotropy(max float32) float32 {
+	if s.Anisotropy > max {
+		return max
+	}
+	if s.Anisotropy < 1 {
+		return 1
+	}
+	return s.Anisotropy
+}
+
+// generate the bound texture's mipmaps, after its image is uploaded.
+func (s Sampling) generate() {
+	if s.Mipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+}
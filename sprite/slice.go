@@ -0,0 +1,160 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// SliceMode of the edges and center of a NineSlice.
+type SliceMode int
+
+const (
+	// Stretch the part to fill its space.
+	Stretch SliceMode = iota
+	// Tile the part at its size in the frame, cutting off the last tile.  Each tile
+	// is a quad, so tiled panels should be drawn through a Batch, which draws them
+	// together, rather than with a draw call each.  A part needing more than
+	// MaxSliceTiles tiles along its edge is stretched instead.
+	Tile
+)
+
+// MaxSliceTiles along each edge of a tiled part of a NineSlice.
+const MaxSliceTiles = 32
+
+// NineSlice divides the frames of a sprite into nine parts by border insets in
+// pixels, so bordered panels can be drawn at any size.  The corners are drawn at
+// their size in the frame, the edges are stretched or tiled along the border and the
+// center fills the rest.  The normal and shadow maps are sliced the same way, so the
+// panel is still lit correctly.  If the panel is smaller than its borders, they are
+// shrunk to fit.
+type NineSlice struct {
+	Left   int
+	Right  int
+	Top    int
+	Bottom int
+	Edges  SliceMode
+	Center SliceMode
+}
+
+// slicePart of a nine-slice panel.  model places the part's quad inside the
+// panel's quad, and tex selects its part of the frame.
+type slicePart struct {
+	model mgl32.Mat4
+	tex   mgl32.Mat3
+}
+
+// span of the panel and the part of the frame drawn in it along one axis, in
+// pixels from the left or bottom.
+type span struct {
+	dst0, dst1 float32
+	src0, src1 float32
+}
+
+// axis of a panel cut into its low border, middle and high border.  The borders
+// are empty if their inset is 0, and the middle if the borders fill the panel.
+type axis struct {
+	low, high []span
+	// middle is stretched across the panel, and tiles tile it.  tiles is empty if
+	// there would be more than MaxSliceTiles.
+	middle, tiles []span
+}
+
+// cut an axis of size pixels of the panel from frames of length pixels with the
+// insets lo and hi.
+func cut(size float32, length, lo, hi int) axis {
+	a := axis{}
+	scale := float32(1.0)
+	if border := float32(lo + hi); border > size {
+		scale = size / border
+	}
+	dstLo, dstHi := float32(lo)*scale, size-float32(hi)*scale
+	srcLo, srcHi := float32(lo), float32(length-hi)
+
+	if dstLo > 0 {
+		a.low = []span{{0, dstLo, 0, srcLo}}
+	}
+	if dstHi < size {
+		a.high = []span{{dstHi, size, srcHi, float32(length)}}
+	}
+	if dstHi <= dstLo || srcHi <= srcLo {
+		return a
+	}
+	a.middle = []span{{dstLo, dstHi, srcLo, srcHi}}
+	tile := srcHi - srcLo
+	if (dstHi-dstLo)/tile > MaxSliceTiles {
+		return a
+	}
+	for d := dstLo; d < dstHi; d += tile {
+		end := float32(math.Min(float64(d+tile), float64(dstHi)))
+		a.tiles = append(a.tiles, span{d, end, srcLo, srcLo + end - d})
+	}
+	return a
+}
+
+// spans of the part i, 0 to 2 from the low border, of the axis.  The middle is
+// tiled if tile is true.
+func (a *axis) spans(i int, tile bool) []span {
+	switch {
+	case i == 0:
+		return a.low
+	case i == 2:
+		return a.high
+	case tile && len(a.tiles) > 0:
+		return a.tiles
+	}
+	return a.middle
+}
+
+// parts of a panel w by h pixels, cut from frames width by height pixels.
+func (n *NineSlice) parts(w, h float32, width, height int) []slicePart {
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+	cols := cut(w, width, n.Left, n.Right)
+	rows := cut(h, height, n.Bottom, n.Top)
+
+	var parts []slicePart
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			// Only the middles are tiled, so the edges are tiled along the border
+			// and not across it.
+			tile := n.Edges == Tile
+			if r == 1 && c == 1 {
+				tile = n.Center == Tile
+			}
+			for _, y := range rows.spans(r, tile) {
+				for _, x := range cols.spans(c, tile) {
+					parts = append(parts, part(x, y, w, h, width, height))
+				}
+			}
+		}
+	}
+	return parts
+}
+
+// part of a panel w by h pixels drawing the spans x and y of frames width by height
+// pixels.
+func part(x, y span, w, h float32, width, height int) slicePart {
+	model := mgl32.Translate3D((x.dst0+x.dst1)/(2*w)-0.5, (y.dst0+y.dst1)/(2*h)-0.5, 0.0)
+	model = model.Mul4(mgl32.Scale3D((x.dst1-x.dst0)/w, (y.dst1-y.dst0)/h, 1.0))
+	// Texture coordinates start at the top of the frame.
+	fw, fh := float32(width), float32(height)
+	tex := mgl32.Translate2D(x.src0/fw, 1.0-y.src1/fh)
+	tex = tex.Mul3(mgl32.Scale2D((x.src1-x.src0)/fw, (y.src1-y.src0)/fh))
+	return slicePart{model: model, tex: tex}
+}
@@ -0,0 +1,102 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestCut(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   float32
+		lo, hi int
+		low    []span
+		middle []span
+		tiles  []span
+		high   []span
+	}{
+		{"larger", 40, 4, 8, []span{{0, 4, 0, 4}}, []span{{4, 32, 4, 12}},
+			[]span{{4, 12, 4, 12}, {12, 20, 4, 12}, {20, 28, 4, 12}, {28, 32, 4, 8}}, []span{{32, 40, 12, 20}}},
+		{"same", 20, 4, 8, []span{{0, 4, 0, 4}}, []span{{4, 12, 4, 12}},
+			[]span{{4, 12, 4, 12}}, []span{{12, 20, 12, 20}}},
+		{"smaller", 6, 4, 8, []span{{0, 2, 0, 4}}, nil, nil, []span{{2, 6, 12, 20}}},
+		{"no borders", 30, 0, 0, nil, []span{{0, 30, 0, 20}}, []span{{0, 20, 0, 20}, {20, 30, 0, 10}}, nil},
+		{"too many tiles", 20*MaxSliceTiles + 1, 0, 0, nil, []span{{0, 20*MaxSliceTiles + 1, 0, 20}}, nil, nil},
+	}
+	for _, tt := range tests {
+		a := cut(tt.size, 20, tt.lo, tt.hi)
+		for _, got := range []struct {
+			part       string
+			got, wants []span
+		}{
+			{"low", a.low, tt.low},
+			{"middle", a.middle, tt.middle},
+			{"tiles", a.tiles, tt.tiles},
+			{"high", a.high, tt.high},
+		} {
+			if len(got.got) != len(got.wants) {
+				t.Errorf("%s: %s was %v, expected %v", tt.name, got.part, got.got, got.wants)
+				continue
+			}
+			for i := range got.got {
+				if got.got[i] != got.wants[i] {
+					t.Errorf("%s: %s was %v, expected %v", tt.name, got.part, got.got, got.wants)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestNineSliceParts(t *testing.T) {
+	tests := []struct {
+		name  string
+		slice NineSlice
+		parts int
+	}{
+		{"stretch", NineSlice{Left: 4, Right: 4, Top: 4, Bottom: 4}, 9},
+		{"tile edges", NineSlice{Left: 4, Right: 4, Top: 4, Bottom: 4, Edges: Tile}, 4 + 4*4 + 1},
+		{"tile center", NineSlice{Left: 4, Right: 4, Top: 4, Bottom: 4, Center: Tile}, 8 + 4*4},
+		{"no sides", NineSlice{Top: 4, Bottom: 4}, 3},
+	}
+	for _, tt := range tests {
+		// 16x16 frames with 8x8 middles, drawn 36 pixels wide and high.
+		parts := tt.slice.parts(36, 36, 16, 16)
+		if len(parts) != tt.parts {
+			t.Errorf("%s: had %d parts, expected %d", tt.name, len(parts), tt.parts)
+			continue
+		}
+
+		// The bottom left corner is unscaled, and has the bottom left of the frame.
+		corner := parts[0]
+		bl := corner.model.Mul4x1(mgl32.Vec4{-0.5, -0.5, 0, 1})
+		tr := corner.model.Mul4x1(mgl32.Vec4{0.5, 0.5, 0, 1})
+		st := corner.tex.Mul3x1(mgl32.Vec3{1, 0, 1})
+		expected := mgl32.Vec2{4, 4}
+		if tt.slice.Left == 0 {
+			expected = mgl32.Vec2{36, 4}
+		}
+		if !aboutTheSame(bl[0], -0.5) || !aboutTheSame(bl[1], -0.5) ||
+			!aboutTheSame((tr[0]+0.5)*36, expected[0]) || !aboutTheSame((tr[1]+0.5)*36, expected[1]) {
+			t.Errorf("%s: bottom left part was %v %v, expected to be %v pixels", tt.name, bl, tr, expected)
+		}
+		if tt.slice.Left != 0 && (!aboutTheSame(st[0], 0.25) || !aboutTheSame(st[1], 0.75)) {
+			t.Errorf("%s: bottom left part's top right was at %v, expected 0.25, 0.75", tt.name, st)
+		}
+	}
+}
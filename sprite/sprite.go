@@ -19,15 +19,19 @@ import (
 	"bytes"
 	"fmt"
 	"image"
+	"image/color"
 	"image/draw"
 	_ "image/png" // register PNG decode
+	"math"
 	"os"
 	"runtime"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/hurricanerix/shade/gen"
+	"github.com/hurricanerix/shade/gpu"
 	"github.com/hurricanerix/shade/light"
+	"github.com/hurricanerix/shade/shader"
 )
 
 func init() {
@@ -44,34 +48,51 @@ type Sprite interface {
 
 // Context TODO doc
 type Context struct {
-	ColorMap        image.Image
-	NormalMap       image.Image
-	Width           int
-	Height          int
-	framesX         int
-	framesY         int
-	vao             uint32
-	vbo             uint32
-	texLoc          uint32
-	normalLoc       uint32
-	model           mgl32.Mat4
-	modelMatrix     int32
-	tex             mgl32.Mat3
-	texMatrix       int32
-	addColorLoc     int32
-	addColor        int32
-	aColorLoc       int32
-	aColor          mgl32.Vec4
-	subColorLoc     int32
-	subColor        int32
-	sColorLoc       int32
-	sColor          mgl32.Vec4
-	AmbientColorLoc int32
-	AmbientColor    mgl32.Vec4
-	LightPosLoc     int32
-	LightColorLoc   int32
-	LightPowerLoc   int32
-	Light           light.Positional
+	ColorMap  image.Image
+	NormalMap image.Image
+	// ShadowMap is a grayscale height map, such as the *.shadow.png assets, which
+	// makes the sprite shadow itself where higher parts block the light.  It is
+	// optional, and must be set before Bind.
+	ShadowMap image.Image
+	// ShadowStrength is how much of the light shadows block, from 0 to 1,
+	// DefaultShadowStrength if it is 0.
+	ShadowStrength float32
+	// ShadowHeight is how many pixels above the sprite white is in the ShadowMap,
+	// DefaultShadowHeight if it is 0.
+	ShadowHeight float32
+	Width        int
+	Height       int
+	framesX      int
+	framesY      int
+	vao          uint32
+	vbo          uint32
+	texLoc       uint32
+	normalLoc    uint32
+	shadowLoc    uint32
+	// shadowTexel is the size of a texel of the shadow map in texture coordinates
+	shadowTexel mgl32.Vec2
+	// shadowFrame is the frame being drawn in texture coordinates, as its min and
+	// max corners, which shadows are cast inside of
+	shadowFrame mgl32.Vec4
+	program     *shader.Program
+	model       mgl32.Mat4
+	tex         mgl32.Mat3
+	colorOps
+	AmbientColor mgl32.Vec4
+	Light        light.Positional
+	// page whose textures a Region is drawn from, and the region of them in
+	// texture coordinates (x, y, width, height)
+	page   *Context
+	region mgl32.Vec4
+	// Sampling of the sprite's textures, used when they are bound.  Regions are
+	// sampled like their page.
+	Sampling Sampling
+	// Batch the sprite is drawn through, if not nil.  Draw and DrawFrame then only
+	// queue the sprite, which is drawn when the batch is flushed.
+	Batch *Batch
+	// Slice, if not nil, draws the sprite as a nine-slice panel, its size scaled by
+	// Effects.Scale without stretching its corners.  See DrawPanel.
+	Slice *NineSlice
 }
 
 // Load
@@ -116,6 +137,7 @@ func New(colorMap, normalMap image.Image, framesX, framesY int) (*Context, error
 		framesX:      framesX,
 		framesY:      framesY,
 		AmbientColor: mgl32.Vec4{1.0, 1.0, 1.0, 1.0},
+		colorOps:     colorOps{mColor: mgl32.Vec4{1.0, 1.0, 1.0, 1.0}},
 	}
 
 	if colorMap != nil {
@@ -141,128 +163,126 @@ func New(colorMap, normalMap image.Image, framesX, framesY int) (*Context, error
 	return &c, nil
 }
 
-// Bind TODO doc
+// Bind the sprite's textures to OpenGL and set it up to draw with program.  Binding
+// again uploads the current ColorMap and NormalMap to the same textures.  Use
+// Release when the sprite is no longer needed.
 func (c *Context) Bind(program uint32) error {
+	if c.page != nil {
+		return c.bindRegion(program)
+	}
 	if c.ColorMap != nil {
-		rgba := image.NewRGBA(c.ColorMap.Bounds())
-
-		draw.Draw(rgba, rgba.Bounds(), c.ColorMap, image.Point{0, 0}, draw.Src)
-
-		gl.GenTextures(1, &c.texLoc)
-		gl.ActiveTexture(gl.TEXTURE0)
-		gl.BindTexture(gl.TEXTURE_2D, c.texLoc)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, 0)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, 0)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
-
-		gl.TexImage2D(
-			gl.TEXTURE_2D,
-			0,
-			gl.RGBA,
-			int32(rgba.Rect.Size().X),
-			int32(rgba.Rect.Size().Y),
-			0,
-			gl.RGBA,
-			gl.UNSIGNED_BYTE,
-			gl.Ptr(rgba.Pix))
-
-		if c.NormalMap != nil {
-			rgba = image.NewRGBA(c.NormalMap.Bounds())
-
-			draw.Draw(rgba, rgba.Bounds(), c.NormalMap, image.Point{0, 0}, draw.Src)
+		c.upload(gl.TEXTURE0, &c.texLoc, c.ColorMap)
+
+		normalMap := c.NormalMap
+		if normalMap == nil {
+			// A single texel facing the viewer lights the sprite evenly, see the
+			// normalmap package to generate a normal map instead.
+			flat := image.NewRGBA(image.Rect(0, 0, 1, 1))
+			flat.SetRGBA(0, 0, flatNormal)
+			normalMap = flat
+		}
+		c.upload(gl.TEXTURE1, &c.normalLoc, normalMap)
+
+		if c.ShadowMap != nil {
+			if c.ShadowMap.Bounds().Size() != c.ColorMap.Bounds().Size() {
+				return fmt.Errorf("shadow map is %v, not the size of the color map %v", c.ShadowMap.Bounds().Size(), c.ColorMap.Bounds().Size())
+			}
+			c.upload(gl.TEXTURE2, &c.shadowLoc, c.ShadowMap)
+			size := c.ShadowMap.Bounds().Size()
+			c.shadowTexel = mgl32.Vec2{1.0 / float32(size.X), 1.0 / float32(size.Y)}
 		}
-
-		gl.GenTextures(1, &c.normalLoc)
-		gl.ActiveTexture(gl.TEXTURE1)
-		gl.BindTexture(gl.TEXTURE_2D, c.normalLoc)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_BASE_LEVEL, 0)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, 0)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.REPEAT)
-
-		gl.TexImage2D(
-			gl.TEXTURE_2D,
-			0,
-			gl.RGBA,
-			int32(rgba.Rect.Size().X),
-			int32(rgba.Rect.Size().Y),
-			0,
-			gl.RGBA,
-			gl.UNSIGNED_BYTE,
-			gl.Ptr(rgba.Pix))
 	}
 
-	gl.UseProgram(program)
-
-	colorMap := gl.GetUniformLocation(program, gl.Str("ColorMap\x00"))
-	gl.Uniform1i(colorMap, 0)
-
-	normalMapLoc := gl.GetUniformLocation(program, gl.Str("NormalMap\x00"))
-	gl.Uniform1i(normalMapLoc, 1)
-
-	c.modelMatrix = gl.GetUniformLocation(program, gl.Str("ModelMatrix\x00"))
-	gl.UniformMatrix4fv(c.modelMatrix, 1, false, &c.model[0])
-
-	c.texMatrix = gl.GetUniformLocation(program, gl.Str("TexMatrix\x00"))
-	gl.UniformMatrix3fv(c.texMatrix, 1, false, &c.tex[0])
-
-	// Add color
-	c.addColorLoc = gl.GetUniformLocation(program, gl.Str("AddColor\x00"))
-	gl.Uniform1i(c.addColor, c.addColor)
-	c.aColorLoc = gl.GetUniformLocation(program, gl.Str("AColor\x00"))
-	gl.UniformMatrix3fv(c.aColorLoc, 1, false, &c.aColor[0])
-
-	// Sub color
-	c.subColorLoc = gl.GetUniformLocation(program, gl.Str("SubColor\x00"))
-	gl.Uniform1i(c.subColor, c.subColor)
-	c.sColorLoc = gl.GetUniformLocation(program, gl.Str("SColor\x00"))
-	gl.UniformMatrix3fv(c.sColorLoc, 1, false, &c.sColor[0])
-
-	c.AmbientColorLoc = gl.GetUniformLocation(program, gl.Str("AmbientColor\x00"))
-	gl.Uniform4fv(c.AmbientColorLoc, 1, &c.AmbientColor[0])
-
-	c.LightPosLoc = gl.GetUniformLocation(program, gl.Str("LightPos\x00"))
-	gl.Uniform3fv(c.LightPosLoc, 1, &c.Light.Pos[0])
-
-	c.LightColorLoc = gl.GetUniformLocation(program, gl.Str("LightColor\x00"))
-	gl.Uniform4fv(c.LightColorLoc, 1, &c.Light.Color[0])
-
-	c.LightPowerLoc = gl.GetUniformLocation(program, gl.Str("LightPower\x00"))
-	gl.Uniform1f(c.LightPowerLoc, c.Light.Power)
+	c.program = shader.Lookup(program)
+	c.program.Use()
+	c.setUniforms(c.program)
 
 	if c.vao == 0 {
 		gl.GenVertexArrays(1, &c.vao)
+		gpu.Created(gpu.VertexArray, c.vao)
 		gl.BindVertexArray(c.vao)
 	}
 
 	if c.vbo == 0 {
 		gl.GenBuffers(1, &c.vbo)
+		gpu.Created(gpu.Buffer, c.vbo)
 	}
 	gl.BindBuffer(gl.ARRAY_BUFFER, c.vbo)
 	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
 
-	mcVertex := uint32(gl.GetAttribLocation(program, gl.Str("MCVertex\x00")))
-	gl.EnableVertexAttribArray(mcVertex)
-	gl.VertexAttribPointer(mcVertex, 3, gl.FLOAT, false, 11*4, gl.PtrOffset(0))
+	c.enableAttrib("MCVertex", 3, 0)
+	c.enableAttrib("MCNormal", 3, 3)
+	c.enableAttrib("MCTangent", 4, 6)
+	c.enableAttrib("TexCoord0", 2, 10)
+
+	return nil
+}
 
-	mcNormal := uint32(gl.GetAttribLocation(program, gl.Str("MCNormal\x00")))
-	gl.EnableVertexAttribArray(mcNormal)
-	gl.VertexAttribPointer(mcNormal, 3, gl.FLOAT, false, 11*4, gl.PtrOffset(3*4))
+// upload img to the texture id on unit, generating the texture if id is 0.
+func (c *Context) upload(unit uint32, id *uint32, img image.Image) {
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, rgba.Bounds().Min, draw.Src)
 
-	mcTangent := uint32(gl.GetAttribLocation(program, gl.Str("MCTangent\x00")))
-	gl.EnableVertexAttribArray(mcTangent)
-	gl.VertexAttribPointer(mcTangent, 3, gl.FLOAT, false, 11*4, gl.PtrOffset(6*4))
+	if *id == 0 {
+		gl.GenTextures(1, id)
+		gpu.Created(gpu.Texture, *id)
+	}
+	gl.ActiveTexture(unit)
+	gl.BindTexture(gl.TEXTURE_2D, *id)
+	c.Sampling.apply()
+
+	gl.TexImage2D(
+		gl.TEXTURE_2D,
+		0,
+		gl.RGBA,
+		int32(rgba.Rect.Size().X),
+		int32(rgba.Rect.Size().Y),
+		0,
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		gl.Ptr(rgba.Pix))
+	c.Sampling.generate()
+}
+
+// Release the sprite's OpenGL objects.  It can be bound again afterwards.  Releasing
+// a Region only forgets its page's objects, release the page itself instead.
+func (c *Context) Release() {
+	if c.page != nil {
+		// The objects belong to the page.
+		c.texLoc, c.normalLoc, c.shadowLoc, c.vao, c.vbo = 0, 0, 0, 0, 0
+		return
+	}
+	gl.DeleteTextures(1, &c.texLoc)
+	gpu.Deleted(gpu.Texture, c.texLoc)
+	gl.DeleteTextures(1, &c.normalLoc)
+	gpu.Deleted(gpu.Texture, c.normalLoc)
+	if c.shadowLoc != 0 {
+		gl.DeleteTextures(1, &c.shadowLoc)
+		gpu.Deleted(gpu.Texture, c.shadowLoc)
+	}
+	gl.DeleteVertexArrays(1, &c.vao)
+	gpu.Deleted(gpu.VertexArray, c.vao)
+	gl.DeleteBuffers(1, &c.vbo)
+	gpu.Deleted(gpu.Buffer, c.vbo)
+	c.texLoc, c.normalLoc, c.shadowLoc, c.vao, c.vbo = 0, 0, 0, 0, 0
+}
 
-	texCoord0 := uint32(gl.GetAttribLocation(program, gl.Str("TexCoord0\x00")))
-	gl.EnableVertexAttribArray(texCoord0)
-	gl.VertexAttribPointer(texCoord0, 2, gl.FLOAT, false, 11*4, gl.PtrOffset(9*4))
+// enableAttrib name of the bound program, made of size floats starting offset floats
+// into each vertex.
+func (c *Context) enableAttrib(name string, size, offset int) {
+	loc := c.program.Attrib(name)
+	if loc < 0 {
+		// Not used by the program
+		return
+	}
+	gl.EnableVertexAttribArray(uint32(loc))
+	gl.VertexAttribPointer(uint32(loc), int32(size), gl.FLOAT, false, vertexSize*4, gl.PtrOffset(offset*4))
+}
 
-	return nil
+// setUniforms of p to the sprite's current state, using p's cached locations.
+func (c *Context) setUniforms(p *shader.Program) {
+	state := c.state(p)
+	state.setUniforms(c.model, c.tex)
 }
 
 // Draw TODO doc
@@ -271,14 +291,39 @@ func (c *Context) Draw(pos mgl32.Vec3, e *Effects) {
 }
 
 type Effects struct {
-	Scale          mgl32.Vec3
-	Tint           mgl32.Vec4
+	Scale mgl32.Vec3
+	// Rotation counter-clockwise around Pivot in radians.
+	Rotation float32
+	// Pivot the sprite rotates around, as a fraction of its size from its bottom
+	// left corner.  The zero value is the bottom left corner, {0.5, 0.5} is the
+	// center.
+	Pivot mgl32.Vec2
+	// FlipX mirrors the sprite horizontally and FlipY vertically, in place.
+	FlipX bool
+	FlipY bool
+	// Tint is added to the sprite's color.
+	Tint mgl32.Vec4
+	// Subtract is subtracted from the sprite's color.
+	Subtract mgl32.Vec4
+	// Modulate multiplies the sprite's color and alpha.  The zero value leaves them
+	// unchanged.
+	Modulate mgl32.Vec4
+	// Fade makes the sprite transparent, from 0 opaque to 1 invisible.
+	Fade float32
+	// Blend mode the sprite is drawn with, Alpha by default.
+	Blend          BlendMode
 	EnableLighting bool
 	AmbientColor   mgl32.Vec4
 	Light          light.Positional
+	// Program to draw with instead of the one the sprite was bound to.  It must
+	// use the same uniform and attribute names as the default shaders where it
+	// needs them, and the camera must also be bound to it.
+	Program *shader.Program
 }
 
-// DrawFrame TODO doc
+// DrawFrame of the sprite sheet with its bottom left corner at pos.  The Z of pos
+// layers sprites, those with a higher Z are drawn on top.  It must be between -93
+// and 6.9 to be inside the camera's view.
 //func (c *Context) DrawFrame(fx, fy int, sx, sy, px, py float32, addColor, subColor, ambientColor *mgl32.Vec4, light *light.Positional) {
 func (c *Context) DrawFrame(frame mgl32.Vec2, pos mgl32.Vec3, e *Effects) {
 	if e == nil {
@@ -287,45 +332,60 @@ func (c *Context) DrawFrame(frame mgl32.Vec2, pos mgl32.Vec3, e *Effects) {
 			Scale: mgl32.Vec3{1.0, 1.0, 1.0},
 		}
 	}
-	c.model = mgl32.Ident4()
-	c.model = c.model.Mul4(mgl32.Translate3D(float32(c.Width*int(e.Scale[0]))/2.0, float32(c.Height*int(e.Scale[1]))/2.0, 0.0))
-	c.model = c.model.Mul4(mgl32.Translate3D(pos[0], pos[1], pos[2]))
-	c.model = c.model.Mul4(mgl32.Scale3D(float32(c.Width)*e.Scale[0], float32(c.Height)*e.Scale[1], 0.0))
-	gl.UniformMatrix4fv(c.modelMatrix, 1, false, &c.model[0])
-
-	c.tex = mgl32.Ident3()
-	c.tex = c.tex.Mul3(mgl32.Scale2D(1.0/float32(c.framesX), 1.0/float32(c.framesY)))
-	c.tex = c.tex.Mul3(mgl32.Translate2D(frame[0], frame[1]))
-	gl.UniformMatrix3fv(c.texMatrix, 1, false, &c.tex[0])
-
-	// TODO change addColor to MaxColor in shader
-	ac := int32(1)
-	c.aColor = e.Tint
-	gl.Uniform4fv(c.aColorLoc, 1, &c.aColor[0])
-	c.addColor = ac
-	gl.Uniform1i(c.addColorLoc, c.addColor)
-
-	/*
-		//MinColor       mgl32.Vec4
-		sc := int32(0)
-		if subColor != nil {
-			sc = 1
-			c.sColor = *subColor
-			gl.Uniform4fv(c.sColorLoc, 1, &c.sColor[0])
-		}
-		c.subColor = sc
-		gl.Uniform1i(c.subColorLoc, c.subColor)
-	*/
+	model := c.modelMatrix(pos, e)
+
+	tex := mgl32.Ident3()
+	if c.page != nil {
+		tex = tex.Mul3(mgl32.Translate2D(c.region[0], c.region[1]))
+		tex = tex.Mul3(mgl32.Scale2D(c.region[2], c.region[3]))
+	}
+	tex = tex.Mul3(mgl32.Scale2D(1.0/float32(c.framesX), 1.0/float32(c.framesY)))
+	tex = tex.Mul3(mgl32.Translate2D(frame[0], frame[1]))
+	c.shadowFrame = texRect(tex)
+
+	c.colorOps = e.colorOps()
 	if e.EnableLighting == true {
 		c.AmbientColor = e.AmbientColor
-		gl.Uniform4fv(c.AmbientColorLoc, 1, &c.AmbientColor[0])
-
 		c.Light = e.Light
-		gl.Uniform3fv(c.LightPosLoc, 1, &c.Light.Pos[0])
-		gl.Uniform4fv(c.LightColorLoc, 1, &c.Light.Color[0])
-		gl.Uniform1f(c.LightPowerLoc, c.Light.Power)
 	}
 
+	p := c.program
+	if e.Program != nil {
+		p = e.Program
+	}
+	if c.Slice == nil {
+		c.model, c.tex = model, tex
+		c.draw(p)
+		return
+	}
+	w := float32(math.Abs(float64(float32(c.Width) * e.Scale[0])))
+	h := float32(math.Abs(float64(float32(c.Height) * e.Scale[1])))
+	for _, part := range c.Slice.parts(w, h, c.Width, c.Height) {
+		c.model, c.tex = model.Mul4(part.model), tex.Mul3(part.tex)
+		c.draw(p)
+	}
+}
+
+// DrawPanel draws the frame of a sprite with a Slice as a panel w by h pixels, with
+// its bottom left corner at pos.  The Scale of e is ignored.
+func (c *Context) DrawPanel(frame mgl32.Vec2, pos mgl32.Vec3, w, h float32, e *Effects) {
+	panel := Effects{}
+	if e != nil {
+		panel = *e
+	}
+	panel.Scale = mgl32.Vec3{w / float32(c.Width), h / float32(c.Height), 1.0}
+	c.DrawFrame(frame, pos, &panel)
+}
+
+// draw the sprite's quad with its current model and texture matrices with p.
+func (c *Context) draw(p *shader.Program) {
+	if c.Batch != nil {
+		c.Batch.add(c.state(p), c.model, c.tex)
+		return
+	}
+	p.Use()
+	c.setUniforms(p)
+
 	gl.BindVertexArray(c.vao)
 
 	gl.ActiveTexture(gl.TEXTURE0)
@@ -334,19 +394,80 @@ func (c *Context) DrawFrame(frame mgl32.Vec2, pos mgl32.Vec3, e *Effects) {
 	gl.ActiveTexture(gl.TEXTURE1)
 	gl.BindTexture(gl.TEXTURE_2D, c.normalLoc)
 
-	gl.DrawArrays(gl.TRIANGLES, 0, 6*2*3)
+	gl.ActiveTexture(gl.TEXTURE2)
+	gl.BindTexture(gl.TEXTURE_2D, c.shadowLoc)
+
+	c.blend.apply()
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(vertices)/vertexSize))
+	drawCalls++
+	if c.blend != Alpha {
+		Alpha.apply()
+	}
+}
+
+// texRect is the rectangle the texture coordinates of the sprite's quad are mapped
+// to by tex, as its min and max corners.
+func texRect(tex mgl32.Mat3) mgl32.Vec4 {
+	a := tex.Mul3x1(mgl32.Vec3{0, 0, 1})
+	b := tex.Mul3x1(mgl32.Vec3{1, 1, 1})
+	r := mgl32.Vec4{a[0], a[1], b[0], b[1]}
+	for i := 0; i < 2; i++ {
+		if r[i] > r[i+2] {
+			r[i], r[i+2] = r[i+2], r[i]
+		}
+	}
+	return r
+}
+
+// modelMatrix placing the sprite's quad at pos with the effects e.
+func (c *Context) modelMatrix(pos mgl32.Vec3, e *Effects) mgl32.Mat4 {
+	w, h := float32(c.Width)*e.Scale[0], float32(c.Height)*e.Scale[1]
+	model := mgl32.Ident4()
+	model = model.Mul4(mgl32.Translate3D(w/2.0, h/2.0, 0.0))
+	model = model.Mul4(mgl32.Translate3D(pos[0], pos[1], pos[2]))
+	if e.Rotation != 0 {
+		// The quad is centered on the origin, move the pivot there to rotate.
+		px, py := (e.Pivot[0]-0.5)*w, (e.Pivot[1]-0.5)*h
+		model = model.Mul4(mgl32.Translate3D(px, py, 0.0))
+		model = model.Mul4(mgl32.HomogRotate3DZ(e.Rotation))
+		model = model.Mul4(mgl32.Translate3D(-px, -py, 0.0))
+	}
+	if e.FlipX {
+		w = -w
+	}
+	if e.FlipY {
+		h = -h
+	}
+	model = model.Mul4(mgl32.Scale3D(w, h, 1.0))
+	return model
 }
 
 // Update TODO doc
 func (c *Context) Update(dt float32) {
 }
 
-// Pos(X, Y, Z), Normal(X, Y, Z), Tangent(X, Y, Z), TextureCo(S, T)
+// DefaultShadowHeight of sprites with a ShadowMap, in pixels.
+const DefaultShadowHeight = 16
+
+// DefaultShadowStrength of sprites with a ShadowMap.
+const DefaultShadowStrength = 0.6
+
+// flatNormal facing out of the screen.
+var flatNormal = color.RGBA{128, 128, 255, 255}
+
+// Number of floats in each vertex.
+const vertexSize = 12
+
+// Pos(X, Y, Z), Normal(X, Y, Z), Tangent(X, Y, Z, W), TextureCo(S, T)
+//
+// Tangent W is the handedness of the tangent space, -1 if the bitangent is
+// mirrored.  Batches use it for flipped sprites, as their vertices are already in
+// world space.
 var vertices = []float32{
-	-0.5, -0.5, -0.5, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 0.0, 1.0,
-	0.5, -0.5, -0.5, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 1.0, 1.0,
-	0.5, 0.5, 0.5, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 1.0, 0.0,
-	-0.5, 0.5, -0.5, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 0.0, 0.0,
-	-0.5, -0.5, -0.5, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 0.0, 1.0,
-	0.5, 0.5, -0.5, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 1.0, 0.0,
+	-0.5, -0.5, 0.0, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 1.0, 0.0, 1.0,
+	0.5, -0.5, 0.0, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 1.0, 1.0, 1.0,
+	0.5, 0.5, 0.0, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 1.0, 1.0, 0.0,
+	-0.5, 0.5, 0.0, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 1.0, 0.0, 0.0,
+	-0.5, -0.5, 0.0, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 1.0, 0.0, 1.0,
+	0.5, 0.5, 0.0, 0.0, 0.0, 1.0, 1.0, 0.0, 0.0, 1.0, 1.0, 0.0,
 }
@@ -0,0 +1,127 @@
+// Copyright 2016 Richard Hawkins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sprite
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestModelMatrix(t *testing.T) {
+	c := Context{Width: 32, Height: 16}
+	tests := []struct {
+		name string
+		e    Effects
+		// bottom left and top right corners of the quad in model space
+		bottomLeft mgl32.Vec2
+		topRight   mgl32.Vec2
+	}{
+		{"none", Effects{}, mgl32.Vec2{10, 20}, mgl32.Vec2{42, 36}},
+		{"flip x", Effects{FlipX: true}, mgl32.Vec2{42, 20}, mgl32.Vec2{10, 36}},
+		{"flip y", Effects{FlipY: true}, mgl32.Vec2{10, 36}, mgl32.Vec2{42, 20}},
+		{"rotate corner", Effects{Rotation: math.Pi / 2}, mgl32.Vec2{10, 20}, mgl32.Vec2{-6, 52}},
+		{"rotate center", Effects{Rotation: math.Pi, Pivot: mgl32.Vec2{0.5, 0.5}}, mgl32.Vec2{42, 36}, mgl32.Vec2{10, 20}},
+		{"scale", Effects{Scale: mgl32.Vec3{1.5, 0.5, 1}}, mgl32.Vec2{10, 20}, mgl32.Vec2{58, 28}},
+	}
+	for _, tt := range tests {
+		if tt.e.Scale == (mgl32.Vec3{}) {
+			tt.e.Scale = mgl32.Vec3{1, 1, 1}
+		}
+		model := c.modelMatrix(mgl32.Vec3{10, 20, 1}, &tt.e)
+		bl := model.Mul4x1(mgl32.Vec4{-0.5, -0.5, 0, 1})
+		tr := model.Mul4x1(mgl32.Vec4{0.5, 0.5, 0, 1})
+		if !aboutTheSame(bl[0], tt.bottomLeft[0]) || !aboutTheSame(bl[1], tt.bottomLeft[1]) ||
+			!aboutTheSame(tr[0], tt.topRight[0]) || !aboutTheSame(tr[1], tt.topRight[1]) {
+			t.Errorf("%s: corners were %v %v, expected %v %v", tt.name, bl, tr, tt.bottomLeft, tt.topRight)
+		}
+		if bl[2] != 1 || tr[2] != 1 {
+			t.Errorf("%s: z was %v %v, expected 1", tt.name, bl[2], tr[2])
+		}
+	}
+}
+
+func TestSamplingAnisotropy(t *testing.T) {
+	tests := []struct {
+		anisotropy float32
+		max        float32
+		want       float32
+	}{
+		{0, 16, 1},
+		{4, 16, 4},
+		{32, 16, 16},
+		{8, 1, 1},
+	}
+	for _, tt := range tests {
+		s := Sampling{Anisotropy: tt.anisotropy}
+		if got := s.anisotropy(tt.max); got != tt.want {
+			t.Errorf("anisotropy %v with max %v was %v, expected %v", tt.anisotropy, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestStateShadow(t *testing.T) {
+	c := Context{}
+	if s := c.state(nil); s.shadow != 0 || s.shadowHeight != 0 {
+		t.Errorf("sprite without a shadow map had shadow %d height %v", s.shadow, s.shadowHeight)
+	}
+
+	c.shadowLoc = 3
+	c.shadowTexel = mgl32.Vec2{1.0 / 64, 1.0 / 32}
+	if s := c.state(nil); s.shadowHeight != DefaultShadowHeight || s.shadowTexel != c.shadowTexel {
+		t.Errorf("shadow height was %v texel %v, expected %v %v", s.shadowHeight, s.shadowTexel, DefaultShadowHeight, c.shadowTexel)
+	}
+	c.ShadowHeight = 4
+	if s := c.state(nil); s.shadowHeight != 4 {
+		t.Errorf("shadow height was %v, expected 4", s.shadowHeight)
+	}
+	if s := c.state(nil); s.shadowStrength != DefaultShadowStrength {
+		t.Error("Expected shadow strength", DefaultShadowStrength, "but found", s.shadowStrength)
+	}
+	c.ShadowStrength = 0.25
+	if s := c.state(nil); s.shadowStrength != 0.25 {
+		t.Error("Expected shadow strength 0.25 but found", s.shadowStrength)
+	}
+
+	// The second of four frames, kept half a texel inside it.
+	c.shadowFrame = mgl32.Vec4{0.25, 0, 0.5, 1}
+	expected := mgl32.Vec4{0.25 + 0.5/64, 0.5 / 32, 0.5 - 0.5/64, 1 - 0.5/32}
+	if s := c.state(nil); s.shadowFrame != expected {
+		t.Error("Expected shadow frame", expected, "but found", s.shadowFrame)
+	}
+}
+
+func TestTexRect(t *testing.T) {
+	tests := []struct {
+		tex      mgl32.Mat3
+		expected mgl32.Vec4
+	}{
+		{mgl32.Ident3(), mgl32.Vec4{0, 0, 1, 1}},
+		// The third of four frames.
+		{mgl32.Scale2D(0.25, 1).Mul3(mgl32.Translate2D(2, 0)), mgl32.Vec4{0.5, 0, 0.75, 1}},
+		// Mirrored coordinates still give the min and max corners.
+		{mgl32.Translate2D(1, 0).Mul3(mgl32.Scale2D(-0.5, 1)), mgl32.Vec4{0.5, 0, 1, 1}},
+	}
+	for _, tt := range tests {
+		got := texRect(tt.tex)
+		for i := range got {
+			if !aboutTheSame(got[i], tt.expected[i]) {
+				t.Error("Expected rect", tt.expected, "but found", got)
+				break
+			}
+		}
+	}
+}